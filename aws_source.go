@@ -0,0 +1,264 @@
+package env_config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	_ FallibleSource = &SSMSource{}
+	_ OriginSource   = &SSMSource{}
+	_ ContextSource  = &SSMSource{}
+	_ Prefetcher     = &SSMSource{}
+	_ FallibleSource = &SecretsManagerSource{}
+	_ OriginSource   = &SecretsManagerSource{}
+	_ ContextSource  = &SecretsManagerSource{}
+	_ Prefetcher     = &SecretsManagerSource{}
+)
+
+// awsClient calls AWS JSON 1.1 APIs such as SSM and Secrets Manager.
+type awsClient struct {
+	region      string
+	credentials AWSCredentials
+	endpoint    string
+	httpClient  *http.Client
+	now         func() time.Time
+}
+
+// AWSOption configures the AWS client of an SSMSource or a
+// SecretsManagerSource.
+type AWSOption func(*awsClient)
+
+// WithAWSRegion sets the region, $AWS_REGION or $AWS_DEFAULT_REGION by
+// default.
+func WithAWSRegion(region string) AWSOption {
+	return func(c *awsClient) {
+		c.region = region
+	}
+}
+
+// WithAWSCredentials sets the credentials, $AWS_ACCESS_KEY_ID,
+// $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN by default.
+func WithAWSCredentials(credentials AWSCredentials) AWSOption {
+	return func(c *awsClient) {
+		c.credentials = credentials
+	}
+}
+
+// WithAWSEndpoint overrides the service endpoint, e.g. for a VPC endpoint or
+// a local emulator.
+func WithAWSEndpoint(endpoint string) AWSOption {
+	return func(c *awsClient) {
+		c.endpoint = strings.TrimRight(endpoint, "/")
+	}
+}
+
+// WithAWSHTTPClient sets the HTTP client, http.DefaultClient by default.
+func WithAWSHTTPClient(client *http.Client) AWSOption {
+	return func(c *awsClient) {
+		if client != nil {
+			c.httpClient = client
+		}
+	}
+}
+
+func newAWSClient(service string, opts []AWSOption) (*awsClient, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	c := &awsClient{
+		region: region,
+		credentials: AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.region == "" {
+		return nil, errors.New("env_config: aws region is not set")
+	}
+	if c.credentials.AccessKeyID == "" || c.credentials.SecretAccessKey == "" {
+		return nil, errors.New("env_config: aws credentials are not set")
+	}
+	if c.endpoint == "" {
+		c.endpoint = "https://" + service + "." + c.region + ".amazonaws.com"
+	}
+	return c, nil
+}
+
+// call sends a signed JSON 1.1 request for target, e.g.
+// AmazonSSM.GetParametersByPath, and decodes the response into out.
+func (c *awsClient) call(ctx context.Context, service, target string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signV4(req, body, c.credentials, c.region, service, c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Type         string `json:"__type"`
+			Message      string `json:"message"`
+			MessageUpper string `json:"Message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		code := failure.Type[strings.LastIndex(failure.Type, "#")+1:]
+		if code == "" {
+			code = resp.Status
+		}
+		message := failure.Message + failure.MessageUpper
+		if message == "" {
+			return fmt.Errorf("%s: %s", target, code)
+		}
+		return fmt.Errorf("%s: %s: %s", target, code, message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// SSMSource serves the parameters stored under a path of AWS Systems Manager
+// Parameter Store. Parameter names are made relative to the path and mapped
+// like nested keys, so with the path /app/prod the parameter
+// /app/prod/database/host serves DATABASE_HOST. Parameters are fetched with
+// GetParametersByPath on first use, and each key reports ssm:<name> as its
+// origin.
+type SSMSource struct {
+	remoteValues
+
+	client     *awsClient
+	path       string
+	decryption bool
+}
+
+// NewSSMSource creates a source for the parameters under path, read
+// recursively. withDecryption decrypts SecureString parameters; without it
+// their encrypted value is served.
+func NewSSMSource(path string, withDecryption bool, opts ...AWSOption) (*SSMSource, error) {
+	client, err := newAWSClient("ssm", opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &SSMSource{
+		client:     client,
+		path:       "/" + strings.Trim(path, "/"),
+		decryption: withDecryption,
+	}
+	s.remoteValues.fetch = s.fetch
+	return s, nil
+}
+
+func (s *SSMSource) fetch(ctx context.Context) (MapSource, map[string]string, error) {
+	type request struct {
+		Path           string
+		Recursive      bool
+		WithDecryption bool
+		NextToken      string `json:",omitempty"`
+	}
+	type response struct {
+		Parameters []struct {
+			Name  string
+			Value string
+		}
+		NextToken string
+	}
+
+	values, origins := MapSource{}, map[string]string{}
+	in := request{Path: s.path, Recursive: true, WithDecryption: s.decryption}
+	for {
+		var response response
+		if err := s.client.call(ctx, "ssm", "AmazonSSM.GetParametersByPath", in, &response); err != nil {
+			return nil, nil, fmt.Errorf("ssm: %w", err)
+		}
+		for _, parameter := range response.Parameters {
+			key := pathKey(strings.TrimPrefix(parameter.Name, s.path), "/")
+			values[key] = parameter.Value
+			origins[key] = "ssm:" + parameter.Name
+		}
+		if response.NextToken == "" {
+			return values, origins, nil
+		}
+		in.NextToken = response.NextToken
+	}
+}
+
+// SecretsManagerSource serves the keys of an AWS Secrets Manager secret whose
+// value is a JSON object, flattened like NewJSONSource does. Keys report
+// secretsmanager:<secret ID> as their origin.
+type SecretsManagerSource struct {
+	remoteValues
+
+	client   *awsClient
+	secretID string
+}
+
+// NewSecretsManagerSource creates a source for a secret, given by name or
+// ARN. The secret is fetched with GetSecretValue on first use.
+func NewSecretsManagerSource(secretID string, opts ...AWSOption) (*SecretsManagerSource, error) {
+	client, err := newAWSClient("secretsmanager", opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &SecretsManagerSource{client: client, secretID: secretID}
+	s.remoteValues.fetch = s.fetch
+	return s, nil
+}
+
+func (s *SecretsManagerSource) fetch(ctx context.Context) (MapSource, map[string]string, error) {
+	var response struct {
+		SecretString *string
+	}
+	in := map[string]string{"SecretId": s.secretID}
+	if err := s.client.call(ctx, "secretsmanager", "secretsmanager.GetSecretValue", in, &response); err != nil {
+		return nil, nil, fmt.Errorf("secretsmanager: %w", err)
+	}
+	if response.SecretString == nil {
+		return nil, nil, fmt.Errorf("secretsmanager: secret %s has no string value", s.secretID)
+	}
+
+	values, err := ParseJSON(strings.NewReader(*response.SecretString))
+	if err != nil {
+		return nil, nil, fmt.Errorf("secretsmanager: secret %s is not a JSON object: %w", s.secretID, err)
+	}
+	origins := make(map[string]string, len(values))
+	for key := range values {
+		origins[key] = "secretsmanager:" + s.secretID
+	}
+	return values, origins, nil
+}
+
+// pathKey maps a hierarchical name such as database/host to the key
+// DATABASE_HOST, like combineKeyPrefix does for nested structs.
+func pathKey(name, separator string) string {
+	var key string
+	for _, segment := range strings.Split(name, separator) {
+		if segment != "" {
+			key = combineKeyPrefix(key, normalizeKey(segment))
+		}
+	}
+	return key
+}
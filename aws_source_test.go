@@ -0,0 +1,271 @@
+package env_config
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testAWSCredentials = AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+// fakeAWS implements GetParametersByPath and GetSecretValue, checking the
+// SigV4 signature of every request.
+type fakeAWS struct {
+	t          *testing.T
+	parameters []map[string]string
+	secrets    map[string]string
+	pageSize   int
+	requests   []map[string]interface{}
+}
+
+func newFakeAWS(t *testing.T) (*fakeAWS, *httptest.Server) {
+	f := &fakeAWS{
+		t:        t,
+		pageSize: 2,
+		parameters: []map[string]string{
+			{"Name": "/app/prod/port", "Type": "String", "Value": "8080"},
+			{"Name": "/app/prod/database/host", "Type": "String", "Value": "db.internal"},
+			{"Name": "/app/prod/database/password", "Type": "SecureString", "Value": "hunter2"},
+			{"Name": "/app/prod/feature-flags", "Type": "StringList", "Value": "a,b"},
+			{"Name": "/app/staging/port", "Type": "String", "Value": "9090"},
+		},
+		secrets: map[string]string{
+			"prod/db": `{"username": "admin", "password": "s3cr3t", "port": 5432, "replica": {"host": "r"}}`,
+			"plain":   `not json`,
+		},
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if !f.validSignature(r, body) {
+		f.fail(w, http.StatusForbidden, "com.amazon.coral.service#InvalidSignatureException", "signature mismatch")
+		return
+	}
+	var in map[string]interface{}
+	assert.NoError(f.t, json.Unmarshal(body, &in))
+	f.requests = append(f.requests, in)
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSSM.GetParametersByPath":
+		path := in["Path"].(string) + "/"
+		var matches []map[string]string
+		for _, parameter := range f.parameters {
+			if strings.HasPrefix(parameter["Name"], path) {
+				if parameter["Type"] == "SecureString" && in["WithDecryption"] != true {
+					parameter = map[string]string{"Name": parameter["Name"], "Value": "AQICAH-encrypted"}
+				}
+				matches = append(matches, parameter)
+			}
+		}
+		start := 0
+		if token, ok := in["NextToken"].(string); ok {
+			start = int(token[0] - '0')
+		}
+		end := start + f.pageSize
+		out := map[string]interface{}{}
+		if end < len(matches) {
+			out["NextToken"] = string(rune('0' + end))
+		} else {
+			end = len(matches)
+		}
+		out["Parameters"] = matches[start:end]
+		json.NewEncoder(w).Encode(out)
+	case "secretsmanager.GetSecretValue":
+		id := in["SecretId"].(string)
+		secret, ok := f.secrets[id]
+		if !ok {
+			f.fail(w, http.StatusBadRequest, "ResourceNotFoundException", "Secrets Manager can't find the specified secret.")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Name": id, "SecretString": secret})
+	default:
+		f.fail(w, http.StatusBadRequest, "UnknownOperationException", "")
+	}
+}
+
+// validSignature signs a copy of r with the test credentials and compares
+// the result.
+func (f *fakeAWS) validSignature(r *http.Request, body []byte) bool {
+	date, err := time.Parse(sigV4TimeFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+	service := "ssm"
+	if strings.HasPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager.") {
+		service = "secretsmanager"
+	}
+	req, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.String(), nil)
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	req.Header.Set("X-Amz-Target", r.Header.Get("X-Amz-Target"))
+	signV4(req, body, testAWSCredentials, "eu-west-1", service, date)
+	return req.Header.Get("Authorization") == r.Header.Get("Authorization")
+}
+
+func (f *fakeAWS) fail(w http.ResponseWriter, status int, kind, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"__type": kind, "message": message})
+}
+
+func testAWSOptions(server *httptest.Server) []AWSOption {
+	return []AWSOption{
+		WithAWSRegion("eu-west-1"),
+		WithAWSCredentials(testAWSCredentials),
+		WithAWSEndpoint(server.URL + "/"),
+		WithAWSHTTPClient(server.Client()),
+	}
+}
+
+func TestSSMSource(t *testing.T) {
+	fake, server := newFakeAWS(t)
+
+	source, err := NewSSMSource("/app/prod/", true, testAWSOptions(server)...)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		key         string
+		wantValue   string
+		wantOrigin  string
+		wantPresent bool
+	}{
+		{key: "PORT", wantValue: "8080", wantOrigin: "ssm:/app/prod/port", wantPresent: true},
+		{key: "DATABASE_HOST", wantValue: "db.internal", wantOrigin: "ssm:/app/prod/database/host", wantPresent: true},
+		{key: "DATABASE_PASSWORD", wantValue: "hunter2", wantOrigin: "ssm:/app/prod/database/password", wantPresent: true},
+		{key: "FEATURE_FLAGS", wantValue: "a,b", wantOrigin: "ssm:/app/prod/feature-flags", wantPresent: true},
+		{key: "MISSING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, present, err := source.LookupErr(tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantPresent, present)
+
+			origin, present := source.Origin(tt.key)
+			assert.Equal(t, tt.wantOrigin, origin)
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+
+	// Two pages, fetched once.
+	assert.Len(t, fake.requests, 2)
+	assert.Equal(t, map[string]interface{}{"Path": "/app/prod", "Recursive": true, "WithDecryption": true}, fake.requests[0])
+	assert.Equal(t, "2", fake.requests[1]["NextToken"])
+
+	type Database struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD;sensitive"`
+	}
+	type Config struct {
+		Port     int      `env:"PORT"`
+		Flags    []string `env:"FEATURE_FLAGS"`
+		Database Database `env:"DATABASE"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source)))
+	assert.Equal(t, Config{Port: 8080, Flags: []string{"a", "b"}, Database: Database{Host: "db.internal", Password: "hunter2"}}, cfg)
+}
+
+func TestSSMSource_WithoutDecryption(t *testing.T) {
+	_, server := newFakeAWS(t)
+
+	source, err := NewSSMSource("app/prod", false, testAWSOptions(server)...)
+	assert.NoError(t, err)
+	value, ok := source.Lookup("DATABASE_PASSWORD")
+	assert.True(t, ok)
+	assert.Equal(t, "AQICAH-encrypted", value)
+}
+
+func TestSecretsManagerSource(t *testing.T) {
+	_, server := newFakeAWS(t)
+
+	source, err := NewSecretsManagerSource("prod/db", testAWSOptions(server)...)
+	assert.NoError(t, err)
+
+	type Config struct {
+		Username    string `env:"USERNAME"`
+		Password    string `env:"PASSWORD;sensitive"`
+		Port        int    `env:"PORT"`
+		ReplicaHost string `env:"REPLICA_HOST"`
+	}
+	var (
+		cfg    Config
+		report Report
+	)
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithReport(&report)))
+	assert.Equal(t, Config{Username: "admin", Password: "s3cr3t", Port: 5432, ReplicaHost: "r"}, cfg)
+	field, _ := report.Field("Config.Password")
+	assert.Equal(t, "secretsmanager:prod/db", field.Origin)
+	assert.Equal(t, redacted, field.Value)
+}
+
+func TestAWSSources_Errors(t *testing.T) {
+	_, server := newFakeAWS(t)
+
+	t.Run("missing configuration", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "")
+		t.Setenv("AWS_ACCESS_KEY_ID", "")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+		_, err := NewSSMSource("/app", true)
+		assert.EqualError(t, err, "env_config: aws region is not set")
+		_, err = NewSecretsManagerSource("id", WithAWSRegion("eu-west-1"))
+		assert.EqualError(t, err, "env_config: aws credentials are not set")
+	})
+
+	t.Run("environment defaults", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+		t.Setenv("AWS_ACCESS_KEY_ID", testAWSCredentials.AccessKeyID)
+		t.Setenv("AWS_SECRET_ACCESS_KEY", testAWSCredentials.SecretAccessKey)
+		t.Setenv("AWS_SESSION_TOKEN", "")
+		source, err := NewSSMSource("/app/staging", true, WithAWSEndpoint(server.URL))
+		assert.NoError(t, err)
+		value, _, err := source.LookupErr("PORT")
+		assert.NoError(t, err)
+		assert.Equal(t, "9090", value)
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		options := append(testAWSOptions(server), WithAWSCredentials(AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wrong"}))
+		source, err := NewSSMSource("/app/prod", true, options...)
+		assert.NoError(t, err)
+
+		type Config struct {
+			Port int `env:"PORT"`
+		}
+		err = LoadConfig(&Config{}, WithSource(source))
+		assert.EqualError(t, err, "env_config: cannot resolve PORT: ssm: AmazonSSM.GetParametersByPath: InvalidSignatureException: signature mismatch")
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		source, err := NewSecretsManagerSource("absent", testAWSOptions(server)...)
+		assert.NoError(t, err)
+		_, ok := source.Lookup("KEY")
+		assert.False(t, ok)
+		_, _, err = source.LookupErr("KEY")
+		assert.EqualError(t, err, "secretsmanager: secretsmanager.GetSecretValue: ResourceNotFoundException: Secrets Manager can't find the specified secret.")
+	})
+
+	t.Run("secret that is not JSON", func(t *testing.T) {
+		source, err := NewSecretsManagerSource("plain", testAWSOptions(server)...)
+		assert.NoError(t, err)
+		_, _, err = source.LookupErr("KEY")
+		assert.ErrorContains(t, err, "secretsmanager: secret plain is not a JSON object")
+	})
+}
+
+func TestPathKey(t *testing.T) {
+	assert.Equal(t, "DATABASE_HOST", pathKey("/database/host", "/"))
+	assert.Equal(t, "APP_MAX_CONNS", pathKey("app//max-conns/", "/"))
+	assert.Equal(t, "", pathKey("/", "/"))
+}
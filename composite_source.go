@@ -0,0 +1,89 @@
+package env_config
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	_ OriginSource   = &CompositeSource{}
+	_ FallibleSource = &CompositeSource{}
+	_ ContextSource  = &CompositeSource{}
+	_ Prefetcher     = &CompositeSource{}
+)
+
+// CompositeSource merges an ordered list of sources. Sources are listed from
+// lowest to highest precedence, so a later source overrides an earlier one:
+//
+//	NewCompositeSource(
+//		Named("defaults", MapSource{"PORT": "8080"}),
+//		dotenv,
+//		OSSource{},
+//	)
+type CompositeSource struct {
+	sources []Source
+}
+
+// NewCompositeSource returns a Source that looks keys up in sources, the last
+// one first. Nil sources are ignored.
+func NewCompositeSource(sources ...Source) *CompositeSource {
+	c := &CompositeSource{}
+	for _, source := range sources {
+		if source != nil {
+			c.sources = append(c.sources, source)
+		}
+	}
+	return c
+}
+
+func (c *CompositeSource) Lookup(key string) (string, bool) {
+	value, ok, _ := c.LookupErr(key)
+	return value, ok
+}
+
+// LookupErr stops at the first failing source, so a lower layer cannot
+// silently replace a value a failing layer may hold.
+func (c *CompositeSource) LookupErr(key string) (string, bool, error) {
+	return c.LookupContext(context.Background(), key)
+}
+
+func (c *CompositeSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	_, value, ok, err := c.find(ctx, key)
+	return value, ok, err
+}
+
+// Prefetch prefetches every source implementing Prefetcher in parallel.
+func (c *CompositeSource) Prefetch(ctx context.Context) error {
+	var prefetchers []Prefetcher
+	for _, source := range c.sources {
+		if prefetcher, ok := source.(Prefetcher); ok {
+			prefetchers = append(prefetchers, prefetcher)
+		}
+	}
+	return errors.Join(prefetchAll(ctx, prefetchers)...)
+}
+
+// Origin reports the origin of key in the source that supplies it. Sources
+// that do not implement OriginSource are reported by their type; wrap them
+// with Named for a readable name.
+func (c *CompositeSource) Origin(key string) (string, bool) {
+	source, _, ok, err := c.find(context.Background(), key)
+	if !ok || err != nil {
+		return "", false
+	}
+	return sourceOrigin(source, key), true
+}
+
+// find returns the highest-precedence source that has key, and its value.
+func (c *CompositeSource) find(ctx context.Context, key string) (Source, string, bool, error) {
+	for i := len(c.sources) - 1; i >= 0; i-- {
+		value, ok, err := lookupSource(ctx, c.sources[i], key)
+		if err != nil {
+			return c.sources[i], "", false, err
+		}
+		if ok {
+			return c.sources[i], value, true, nil
+		}
+	}
+	return nil, "", false, nil
+}
@@ -0,0 +1,79 @@
+package env_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeSource(t *testing.T) {
+	t.Setenv("COMPOSITE_OS", "os")
+
+	source := NewCompositeSource(
+		Named("defaults", MapSource{"A": "default", "B": "default", "COMPOSITE_OS": "default"}),
+		nil,
+		MapSource{"B": "map", "EMPTY": ""},
+		OSSource{},
+	)
+
+	tests := []struct {
+		name        string
+		key         string
+		wantValue   string
+		wantOrigin  string
+		wantPresent bool
+	}{
+		{
+			name:        "lowest layer",
+			key:         "A",
+			wantValue:   "default",
+			wantOrigin:  "defaults",
+			wantPresent: true,
+		},
+		{
+			name:        "unnamed layer overrides",
+			key:         "B",
+			wantValue:   "map",
+			wantOrigin:  "env_config.MapSource",
+			wantPresent: true,
+		},
+		{
+			name:        "empty value is present",
+			key:         "EMPTY",
+			wantValue:   "",
+			wantOrigin:  "env_config.MapSource",
+			wantPresent: true,
+		},
+		{
+			name:        "environment wins",
+			key:         "COMPOSITE_OS",
+			wantValue:   "os",
+			wantOrigin:  OriginEnvironment,
+			wantPresent: true,
+		},
+		{
+			name: "missing key",
+			key:  "COMPOSITE_MISSING",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, present := source.Lookup(tt.key)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantPresent, present)
+
+			origin, present := source.Origin(tt.key)
+			assert.Equal(t, tt.wantOrigin, origin)
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+}
+
+func TestCompositeSource_NestedOrigin(t *testing.T) {
+	inner := NewCompositeSource(Named("inner", MapSource{"KEY": "value"}))
+	source := NewCompositeSource(Named("outer", MapSource{"KEY": "outer"}), inner)
+
+	origin, ok := source.Origin("KEY")
+	assert.True(t, ok)
+	assert.Equal(t, "inner", origin)
+}
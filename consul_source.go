@@ -0,0 +1,192 @@
+package env_config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	_ FallibleSource = &ConsulSource{}
+	_ OriginSource   = &ConsulSource{}
+	_ ContextSource  = &ConsulSource{}
+	_ Prefetcher     = &ConsulSource{}
+)
+
+// ConsulSource serves the keys stored under a prefix of the Consul KV store,
+// read with a recursive /v1/kv query on first use. Key paths map to keys like
+// nested structs do, so app/database/host serves APP_DATABASE_HOST. Folders
+// are skipped. The origin of a key is consul:<path>.
+type ConsulSource struct {
+	remoteValues
+
+	address    string
+	prefix     string
+	token      string
+	datacenter string
+	httpClient *http.Client
+
+	// index is the X-Consul-Index of the last read, used by Wait.
+	index atomic.Uint64
+}
+
+// ConsulOption configures a ConsulSource.
+type ConsulOption func(*ConsulSource)
+
+// WithConsulAddress sets the Consul address, $CONSUL_HTTP_ADDR or
+// http://127.0.0.1:8500 by default.
+func WithConsulAddress(address string) ConsulOption {
+	return func(s *ConsulSource) {
+		s.address = address
+	}
+}
+
+// WithConsulToken sets the ACL token, $CONSUL_HTTP_TOKEN by default.
+func WithConsulToken(token string) ConsulOption {
+	return func(s *ConsulSource) {
+		s.token = token
+	}
+}
+
+// WithConsulDatacenter queries another datacenter than the agent's.
+func WithConsulDatacenter(datacenter string) ConsulOption {
+	return func(s *ConsulSource) {
+		s.datacenter = datacenter
+	}
+}
+
+// WithConsulHTTPClient sets the HTTP client, http.DefaultClient by default.
+func WithConsulHTTPClient(client *http.Client) ConsulOption {
+	return func(s *ConsulSource) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// NewConsulSource creates a source for the keys under prefix, such as
+// "app". The prefix is a folder: "app" serves app/database/host but not
+// application/name.
+func NewConsulSource(prefix string, opts ...ConsulOption) (*ConsulSource, error) {
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	s := &ConsulSource{
+		address:    os.Getenv("CONSUL_HTTP_ADDR"),
+		prefix:     prefix,
+		token:      os.Getenv("CONSUL_HTTP_TOKEN"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.address == "" {
+		s.address = "127.0.0.1:8500"
+	}
+	if !strings.Contains(s.address, "://") {
+		s.address = "http://" + s.address
+	}
+	s.address = strings.TrimRight(s.address, "/")
+	if u, err := url.Parse(s.address); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("env_config: invalid consul address %q", s.address)
+	}
+	s.remoteValues.fetch = func(ctx context.Context) (MapSource, map[string]string, error) {
+		values, origins, index, err := s.query(ctx, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.index.Store(index)
+		return values, origins, nil
+	}
+	return s, nil
+}
+
+// Wait blocks until a key under the prefix changes, then reloads the values
+// served by the source. It uses Consul blocking queries and returns early
+// when ctx is done. Call it in a loop to follow changes; reloading the
+// configuration is up to the caller.
+func (s *ConsulSource) Wait(ctx context.Context) error {
+	if err := s.Prefetch(ctx); err != nil {
+		return err
+	}
+	for {
+		index := s.index.Load()
+		values, origins, next, err := s.query(ctx, index)
+		if err != nil {
+			return err
+		}
+		if next == index {
+			continue
+		}
+		// The index may also go backwards, e.g. after a snapshot restore;
+		// blocking on the new one is then valid again. Either way it can
+		// move without the keys changing, so only a change of the values
+		// ends the wait.
+		s.index.Store(next)
+		if s.remoteValues.set(values, origins) {
+			return nil
+		}
+	}
+}
+
+// query reads the keys under the prefix. With a non-zero index it is a
+// blocking query that returns once the index moves past it. The returned
+// index is at least 1.
+func (s *ConsulSource) query(ctx context.Context, index uint64) (MapSource, map[string]string, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if s.datacenter != "" {
+		query.Set("dc", s.datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+	}
+	endpoint := s.address + "/v1/kv/" + (&url.URL{Path: s.prefix}).EscapedPath() + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Consul-Token", s.token)
+	}
+
+	var pairs []struct {
+		Key   string
+		Value *string
+	}
+	resp, err := doJSON(s.httpClient, req, &pairs)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// No key under the prefix.
+		err = nil
+	}
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("consul: read %s: %w", s.prefix, err)
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	// An index of 0, e.g. when the header is missing, would make the next
+	// query non-blocking, so Consul documents clamping it to at least 1.
+	next = max(next, 1)
+
+	values, origins := MapSource{}, map[string]string{}
+	for _, pair := range pairs {
+		if strings.HasSuffix(pair.Key, "/") {
+			continue
+		}
+		var value []byte
+		if pair.Value != nil {
+			if value, err = base64.StdEncoding.DecodeString(*pair.Value); err != nil {
+				return nil, nil, 0, fmt.Errorf("consul: key %s: %w", pair.Key, err)
+			}
+		}
+		key := pathKey(pair.Key, "/")
+		values[key] = string(value)
+		origins[key] = "consul:" + pair.Key
+	}
+	return values, origins, next, nil
+}
@@ -0,0 +1,253 @@
+package env_config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsul implements recursive /v1/kv reads with blocking queries.
+type fakeConsul struct {
+	mu      sync.Mutex
+	token   string
+	index   uint64
+	kv      map[string]*string
+	changed chan struct{}
+	queries []string
+	// noIndex omits the X-Consul-Index header, as some proxies do.
+	noIndex bool
+}
+
+func newFakeConsul(t *testing.T) (*fakeConsul, *httptest.Server) {
+	value := func(s string) *string { return &s }
+	c := &fakeConsul{
+		token:   "acl-token",
+		index:   10,
+		changed: make(chan struct{}),
+		kv: map[string]*string{
+			"app/":                nil,
+			"app/database/host":   value("db.internal"),
+			"app/database/port":   value("5432"),
+			"app/feature-flags":   value("a,b"),
+			"app/empty":           nil,
+			"other/database/host": value("ignored"),
+			"application/name":    value("ignored"),
+		},
+	}
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+	return c, server
+}
+
+func (c *fakeConsul) put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.kv[key] = &value
+	c.index++
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != c.token {
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+	c.mu.Lock()
+	c.queries = append(c.queries, r.URL.RawQuery)
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index > 0 && (c.noIndex || index >= c.index) {
+		changed := c.changed
+		c.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		c.mu.Lock()
+	}
+	defer c.mu.Unlock()
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	var pairs []map[string]interface{}
+	for key, value := range c.kv {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		pair := map[string]interface{}{"Key": key, "Value": nil, "ModifyIndex": c.index}
+		if value != nil {
+			pair["Value"] = base64.StdEncoding.EncodeToString([]byte(*value))
+		}
+		pairs = append(pairs, pair)
+	}
+	if !c.noIndex {
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(c.index, 10))
+	}
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(pairs)
+}
+
+func TestConsulSource(t *testing.T) {
+	fake, server := newFakeConsul(t)
+
+	source, err := NewConsulSource("app/", WithConsulAddress(server.URL), WithConsulToken("acl-token"), WithConsulDatacenter("dc2"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		key         string
+		wantValue   string
+		wantOrigin  string
+		wantPresent bool
+	}{
+		{key: "APP_DATABASE_HOST", wantValue: "db.internal", wantOrigin: "consul:app/database/host", wantPresent: true},
+		{key: "APP_FEATURE_FLAGS", wantValue: "a,b", wantOrigin: "consul:app/feature-flags", wantPresent: true},
+		{key: "APP_EMPTY", wantValue: "", wantOrigin: "consul:app/empty", wantPresent: true},
+		{key: "APP", wantPresent: false},
+		{key: "OTHER_DATABASE_HOST", wantPresent: false},
+		{key: "APPLICATION_NAME", wantPresent: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, present, err := source.LookupErr(tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantPresent, present)
+
+			origin, present := source.Origin(tt.key)
+			assert.Equal(t, tt.wantOrigin, origin)
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+	assert.Equal(t, []string{"dc=dc2&recurse=true"}, fake.queries)
+
+	type Database struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	type Config struct {
+		Database Database `env:"DATABASE"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithPrefix("APP")))
+	assert.Equal(t, Config{Database: Database{Host: "db.internal", Port: 5432}}, cfg)
+}
+
+func TestConsulSource_Wait(t *testing.T) {
+	fake, server := newFakeConsul(t)
+	source, err := NewConsulSource("app", WithConsulAddress(strings.TrimPrefix(server.URL, "http://")), WithConsulToken("acl-token"))
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- source.Wait(context.Background())
+	}()
+
+	// Let Wait time out at least once with an unchanged index.
+	time.Sleep(80 * time.Millisecond)
+	fake.put("app/database/host", "db2.internal")
+	assert.NoError(t, <-done)
+
+	value, _ := source.Lookup("APP_DATABASE_HOST")
+	assert.Equal(t, "db2.internal", value)
+	assert.Contains(t, fake.queries, "index=10&recurse=true")
+	assert.Equal(t, uint64(11), source.index.Load())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, source.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestConsulSource_WaitIndexReset(t *testing.T) {
+	fake, server := newFakeConsul(t)
+	source, err := NewConsulSource("app", WithConsulAddress(server.URL), WithConsulToken("acl-token"))
+	assert.NoError(t, err)
+	assert.NoError(t, source.Prefetch(context.Background()))
+
+	// A snapshot restore moves the index back without changing the keys.
+	fake.mu.Lock()
+	fake.index = 3
+	fake.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, source.Wait(ctx), context.DeadlineExceeded)
+	assert.Equal(t, uint64(3), source.index.Load())
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, "index=10&recurse=true", fake.queries[1])
+	for _, query := range fake.queries[2:] {
+		assert.Equal(t, "index=3&recurse=true", query)
+	}
+}
+
+func TestConsulSource_WaitWithoutIndex(t *testing.T) {
+	fake, server := newFakeConsul(t)
+	fake.noIndex = true
+	source, err := NewConsulSource("app", WithConsulAddress(server.URL), WithConsulToken("acl-token"))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, source.Wait(ctx), context.DeadlineExceeded)
+
+	// Every query after the first one blocks instead of spinning.
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.LessOrEqual(t, len(fake.queries), 4)
+	for _, query := range fake.queries[1:] {
+		assert.Equal(t, "index=1&recurse=true", query)
+	}
+}
+
+func TestConsulSource_Errors(t *testing.T) {
+	_, server := newFakeConsul(t)
+
+	t.Run("denied", func(t *testing.T) {
+		source, err := NewConsulSource("app", WithConsulAddress(server.URL), WithConsulToken("wrong"))
+		assert.NoError(t, err)
+		_, _, err = source.LookupErr("APP_DATABASE_HOST")
+		assert.EqualError(t, err, "consul: read app/: 403 Forbidden: ACL not found")
+		assert.Error(t, source.Wait(context.Background()))
+	})
+
+	t.Run("empty prefix", func(t *testing.T) {
+		source, err := NewConsulSource("absent/", WithConsulAddress(server.URL), WithConsulToken("acl-token"))
+		assert.NoError(t, err)
+		_, ok, err := source.LookupErr("ABSENT_KEY")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("environment defaults", func(t *testing.T) {
+		t.Setenv("CONSUL_HTTP_ADDR", server.URL)
+		t.Setenv("CONSUL_HTTP_TOKEN", "acl-token")
+		source, err := NewConsulSource("app")
+		assert.NoError(t, err)
+		value, ok := source.Lookup("APP_DATABASE_PORT")
+		assert.True(t, ok)
+		assert.Equal(t, "5432", value)
+
+		t.Setenv("CONSUL_HTTP_ADDR", "")
+		source, err = NewConsulSource("app")
+		assert.NoError(t, err)
+		assert.Equal(t, "http://127.0.0.1:8500", source.address)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := NewConsulSource("app", WithConsulAddress("http://"))
+		assert.EqualError(t, err, `env_config: invalid consul address "http:"`)
+	})
+}
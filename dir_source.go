@@ -0,0 +1,172 @@
+package env_config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DockerSecretsDir is where Docker and Compose mount secrets.
+	DockerSecretsDir = "/run/secrets"
+	// DefaultMaxFileSize is the largest file a DirSource reads by default,
+	// the size limit of a Kubernetes Secret.
+	DefaultMaxFileSize = 1 << 20
+
+	// kubernetesDataDir is the symlink Kubernetes swaps atomically to the
+	// latest version of a ConfigMap or Secret volume.
+	kubernetesDataDir = "..data"
+)
+
+var _ OriginSource = &DirSource{}
+
+// DirSource serves one key per file, the layout of Kubernetes ConfigMap and
+// Secret volumes, Docker secrets and systemd credentials. Files are read once
+// when the source is created.
+type DirSource struct {
+	dir         string
+	normalize   func(string) string
+	trimNewline bool
+	maxFileSize int64
+	values      MapSource
+	origins     map[string]string
+}
+
+// DirOption configures a DirSource.
+type DirOption func(*DirSource)
+
+// WithNameNormalizer sets how file names map to keys. By default names are
+// uppercased and characters other than letters, digits and underscores
+// become underscores, so db-password serves DB_PASSWORD. A nil normalizer
+// keeps names as they are.
+func WithNameNormalizer(normalize func(name string) string) DirOption {
+	return func(s *DirSource) {
+		if normalize == nil {
+			normalize = func(name string) string { return name }
+		}
+		s.normalize = normalize
+	}
+}
+
+// WithTrimNewline sets whether a single trailing newline is removed from
+// each value, which editors and echo usually add. It is enabled by default.
+func WithTrimNewline(trim bool) DirOption {
+	return func(s *DirSource) {
+		s.trimNewline = trim
+	}
+}
+
+// WithMaxFileSize sets the largest file, in bytes, the source accepts,
+// DefaultMaxFileSize by default.
+func WithMaxFileSize(size int64) DirOption {
+	return func(s *DirSource) {
+		s.maxFileSize = size
+	}
+}
+
+// NewDirSource reads every regular file in dir, following symlinks. Hidden
+// entries and subdirectories are skipped. When dir holds a Kubernetes ..data
+// symlink, files are read through it so all keys come from the same version
+// of the volume. A file larger than the size limit, or two files mapping to
+// the same key, is an error.
+func NewDirSource(dir string, opts ...DirOption) (*DirSource, error) {
+	s := &DirSource{
+		dir:         dir,
+		normalize:   normalizeKey,
+		trimNewline: true,
+		maxFileSize: DefaultMaxFileSize,
+		values:      MapSource{},
+		origins:     map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	root := dir
+	if resolved, err := filepath.EvalSymlinks(filepath.Join(dir, kubernetesDataDir)); err == nil {
+		root = resolved
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(root, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		value, err := s.readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key := s.normalize(name)
+		origin := filepath.Join(dir, name)
+		if other, exists := s.origins[key]; exists {
+			return nil, fmt.Errorf("env_config: %s and %s both map to key %s", other, origin, key)
+		}
+		s.values[key] = value
+		s.origins[key] = origin
+	}
+	return s, nil
+}
+
+// NewCredentialsSource reads the systemd credentials directory named by
+// $CREDENTIALS_DIRECTORY.
+func NewCredentialsSource(opts ...DirOption) (*DirSource, error) {
+	dir, ok := os.LookupEnv("CREDENTIALS_DIRECTORY")
+	if !ok || dir == "" {
+		return nil, errors.New("env_config: CREDENTIALS_DIRECTORY is not set")
+	}
+	return NewDirSource(dir, opts...)
+}
+
+func (s *DirSource) readFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// Read one byte past the limit to detect larger files without trusting
+	// the size reported by Stat.
+	data, err := io.ReadAll(io.LimitReader(f, s.maxFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > s.maxFileSize {
+		return "", fmt.Errorf("env_config: %s is larger than %d bytes", path, s.maxFileSize)
+	}
+
+	value := string(data)
+	if s.trimNewline {
+		value = strings.TrimSuffix(value, "\n")
+		value = strings.TrimSuffix(value, "\r")
+	}
+	return value, nil
+}
+
+func (s *DirSource) Lookup(key string) (string, bool) {
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Origin reports the path of the file that holds key.
+func (s *DirSource) Origin(key string) (string, bool) {
+	origin, ok := s.origins[key]
+	return origin, ok
+}
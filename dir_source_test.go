@@ -0,0 +1,166 @@
+package env_config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDirSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"db-password": "hunter2\n",
+		"API_KEY":     "key\r\n",
+		"multi.line":  "a\nb\n\n",
+		"empty":       "",
+		".hidden":     "skipped",
+		"sub/ignored": "skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "API_KEY"), filepath.Join(dir, "linked")))
+
+	tests := []struct {
+		name string
+		opts []DirOption
+		want MapSource
+	}{
+		{
+			name: "defaults",
+			want: MapSource{
+				"DB_PASSWORD": "hunter2",
+				"API_KEY":     "key",
+				"MULTI_LINE":  "a\nb\n",
+				"EMPTY":       "",
+				"LINKED":      "key",
+			},
+		},
+		{
+			name: "raw names and values",
+			opts: []DirOption{WithNameNormalizer(nil), WithTrimNewline(false)},
+			want: MapSource{
+				"db-password": "hunter2\n",
+				"API_KEY":     "key\r\n",
+				"multi.line":  "a\nb\n\n",
+				"empty":       "",
+				"linked":      "key\r\n",
+			},
+		},
+		{
+			name: "custom normalizer",
+			opts: []DirOption{WithNameNormalizer(func(name string) string { return "SECRET_" + normalizeKey(name) })},
+			want: MapSource{
+				"SECRET_DB_PASSWORD": "hunter2",
+				"SECRET_API_KEY":     "key",
+				"SECRET_MULTI_LINE":  "a\nb\n",
+				"SECRET_EMPTY":       "",
+				"SECRET_LINKED":      "key",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewDirSource(dir, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, source.values)
+		})
+	}
+
+	source, err := NewDirSource(dir)
+	assert.NoError(t, err)
+	value, ok := source.Lookup("DB_PASSWORD")
+	assert.True(t, ok)
+	assert.Equal(t, "hunter2", value)
+	origin, ok := source.Origin("DB_PASSWORD")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "db-password"), origin)
+	_, ok = source.Lookup("MISSING")
+	assert.False(t, ok)
+}
+
+func TestNewDirSource_Kubernetes(t *testing.T) {
+	// Kubernetes mounts each key as a symlink into ..data, itself a symlink
+	// to a timestamped directory that is swapped on update.
+	dir := t.TempDir()
+	for version, password := range map[string]string{"..2024_01_01": "old", "..2024_02_01": "new"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, version, "password"), []byte(password), 0o600))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, version, "username"), []byte("admin"), 0o600))
+	}
+	assert.NoError(t, os.Symlink("..2024_02_01", filepath.Join(dir, "..data")))
+	for _, name := range []string{"password", "username"} {
+		assert.NoError(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+	}
+
+	source, err := NewDirSource(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, MapSource{"PASSWORD": "new", "USERNAME": "admin"}, source.values)
+	origin, _ := source.Origin("PASSWORD")
+	assert.Equal(t, filepath.Join(dir, "password"), origin)
+
+	type Config struct {
+		Username string `env:"USERNAME"`
+		Password string `env:"PASSWORD;sensitive"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source)))
+	assert.Equal(t, Config{Username: "admin", Password: "new"}, cfg)
+}
+
+func TestNewDirSource_Errors(t *testing.T) {
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := NewDirSource(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("file above the size limit", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "big")
+		assert.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", 11)), 0o600))
+
+		_, err := NewDirSource(dir, WithMaxFileSize(10))
+		assert.EqualError(t, err, "env_config: "+path+" is larger than 10 bytes")
+
+		_, err = NewDirSource(dir, WithMaxFileSize(11))
+		assert.NoError(t, err)
+	})
+
+	t.Run("names mapping to the same key", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "db-host"), nil, 0o600))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "db_host"), nil, 0o600))
+
+		_, err := NewDirSource(dir)
+		assert.EqualError(t, err, "env_config: "+filepath.Join(dir, "db-host")+" and "+filepath.Join(dir, "db_host")+" both map to key DB_HOST")
+	})
+
+	t.Run("dangling symlink", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.Symlink(filepath.Join(dir, "nowhere"), filepath.Join(dir, "broken")))
+
+		_, err := NewDirSource(dir)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestNewCredentialsSource(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("abc\n"), 0o600))
+
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	_, err := NewCredentialsSource()
+	assert.EqualError(t, err, "env_config: CREDENTIALS_DIRECTORY is not set")
+
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	source, err := NewCredentialsSource()
+	assert.NoError(t, err)
+	value, ok := source.Lookup("TOKEN")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", value)
+}
@@ -0,0 +1,316 @@
+package env_config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// NewDotenvSource parses the given .env files into a MapSource without
+// touching the process environment. Later files override earlier ones, and
+// ${VAR} references resolve against keys read so far, then the process
+// environment.
+func NewDotenvSource(paths ...string) (MapSource, error) {
+	values := MapSource{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := parseDotenv(path, string(data), values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// ParseDotenv parses dotenv syntax from r:
+//
+//	# comments, blank lines and an optional "export " prefix are allowed
+//	export HOST=localhost        # inline comment after whitespace
+//	LITERAL='no $expansion or \escapes'
+//	MESSAGE="tab\tnew line\n and ${HOST}"
+//	CERT="-----BEGIN-----
+//	multiple lines
+//	-----END-----"
+//	URL=http://${HOST}:$PORT
+//
+// Double-quoted values support the escapes \n \r \t \\ \" and \$ and may span
+// several lines. Unquoted and double-quoted values expand $VAR, ${VAR} and
+// ${VAR:-default} from keys defined earlier, then the process environment.
+func ParseDotenv(r io.Reader) (MapSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	values := MapSource{}
+	if err := parseDotenv("", string(data), values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+type dotenvParser struct {
+	file    string
+	src     string
+	pos     int
+	line    int
+	values  MapSource
+	origins map[string]string
+	// env, when set, wins over values when expanding ${VAR}.
+	env Source
+}
+
+// parseDotenv parses src into values.
+func parseDotenv(file, src string, values MapSource) error {
+	return (&dotenvParser{file: file, src: src, line: 1, values: values}).parse()
+}
+
+// parse reads every entry of src into values. When origins is not nil, it
+// records file as the origin of every key set.
+func (p *dotenvParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		if err := p.parseEntry(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *dotenvParser) parseEntry() error {
+	line := p.line
+	if strings.HasPrefix(p.src[p.pos:], "export ") || strings.HasPrefix(p.src[p.pos:], "export\t") {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+
+	start := p.pos
+	for !p.eof() && isDotenvKeyChar(p.peek()) {
+		p.pos++
+	}
+	key := p.src[start:p.pos]
+	if key == "" || isDigit(key[0]) {
+		return p.errorf(line, "invalid key %q", p.restOfLine(start))
+	}
+
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf(line, "expected = after key %s", key)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case p.eof():
+	case p.peek() == '\'':
+		value, err = p.parseSingleQuoted()
+	case p.peek() == '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value = p.parseUnquoted()
+	}
+	if err != nil {
+		return err
+	}
+
+	p.values[key] = value
+	if p.origins != nil {
+		p.origins[key] = p.file
+	}
+	return nil
+}
+
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	line := p.line
+	p.pos++
+	end := strings.IndexByte(p.src[p.pos:], '\'')
+	if end < 0 {
+		return "", p.errorf(line, "unterminated single-quoted value")
+	}
+	value := p.src[p.pos : p.pos+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 1
+	return value, p.endOfValue()
+}
+
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	line := p.line
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, "unterminated double-quoted value")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), p.endOfValue()
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch next := p.src[p.pos]; next {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '$':
+				b.WriteByte(next)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(next)
+			}
+			p.pos++
+		case c == '$':
+			b.WriteString(p.expandVariable())
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *dotenvParser) parseUnquoted() string {
+	var b strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && p.pos > 0 && isSpace(p.src[p.pos-1]) {
+			p.skipLine()
+			break
+		}
+		if c == '$' {
+			b.WriteString(p.expandVariable())
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return strings.TrimRight(b.String(), " \t\r")
+}
+
+// expandVariable reads $VAR, ${VAR} or ${VAR:-default} at the current
+// position and returns its value. A lone $ is kept as is.
+func (p *dotenvParser) expandVariable() string {
+	p.pos++
+	if !p.eof() && p.peek() == '{' {
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return "$"
+		}
+		expr := p.src[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+		name, fallback, hasFallback := strings.Cut(expr, ":-")
+		if value, ok := p.lookup(name); ok && (value != "" || !hasFallback) {
+			return value
+		}
+		return fallback
+	}
+
+	start := p.pos
+	for !p.eof() && isDotenvKeyChar(p.peek()) && p.peek() != '.' {
+		p.pos++
+	}
+	if start == p.pos {
+		return "$"
+	}
+	value, _ := p.lookup(p.src[start:p.pos])
+	return value
+}
+
+func (p *dotenvParser) lookup(name string) (string, bool) {
+	if p.env != nil {
+		if value, ok := p.env.Lookup(name); ok {
+			return value, true
+		}
+	}
+	if value, ok := p.values[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// endOfValue accepts trailing whitespace and a comment after a quoted value.
+func (p *dotenvParser) endOfValue() error {
+	line := p.line
+	p.skipSpaces()
+	switch {
+	case p.eof():
+		return nil
+	case p.peek() == '#':
+		p.skipLine()
+		return nil
+	case p.peek() == '\n' || p.peek() == '\r':
+		return nil
+	}
+	return p.errorf(line, "unexpected %q after quoted value", p.restOfLine(p.pos))
+}
+
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() && (isSpace(p.peek()) || p.peek() == '\n' || p.peek() == '\r') {
+		if p.peek() == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) restOfLine(start int) string {
+	end := strings.IndexByte(p.src[start:], '\n')
+	if end < 0 {
+		return strings.TrimSpace(p.src[start:])
+	}
+	return strings.TrimSpace(p.src[start : start+end])
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) errorf(line int, format string, args ...interface{}) error {
+	return &SyntaxError{File: p.file, Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isDotenvKeyChar(c byte) bool {
+	return c == '_' || c == '.' || isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
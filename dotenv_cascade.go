@@ -0,0 +1,107 @@
+package env_config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DotenvCascade is a Source that layers the conventional .env files of a
+// directory, from lowest to highest precedence:
+//
+//	.env
+//	.env.local
+//	.env.<profile>
+//	.env.<profile>.local
+//
+// The process environment always wins over every file, including when
+// expanding ${VAR} references. Missing files are skipped.
+type DotenvCascade struct {
+	env     Source
+	profile string
+	files   []string
+	values  MapSource
+	origins map[string]string
+}
+
+var _ OriginSource = &DotenvCascade{}
+
+// NewDotenvCascade loads the .env cascade from dir. The profile is read from
+// the environment variable profileVar, such as APP_ENV, falling back to its
+// value in .env or .env.local. Without a profile only .env and .env.local are
+// read.
+func NewDotenvCascade(dir, profileVar string) (*DotenvCascade, error) {
+	c := &DotenvCascade{
+		env:     OSSource{},
+		values:  MapSource{},
+		origins: map[string]string{},
+	}
+
+	if err := c.load(filepath.Join(dir, ".env"), filepath.Join(dir, ".env.local")); err != nil {
+		return nil, err
+	}
+
+	profile, ok := c.env.Lookup(profileVar)
+	if !ok {
+		profile = c.values[profileVar]
+	}
+	c.profile = profile
+	if profile == "" {
+		return c, nil
+	}
+
+	base := filepath.Join(dir, ".env."+profile)
+	if err := c.load(base, base+".local"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *DotenvCascade) load(paths ...string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		p := &dotenvParser{file: path, src: string(data), line: 1, values: c.values, origins: c.origins, env: c.env}
+		if err := p.parse(); err != nil {
+			return err
+		}
+		c.files = append(c.files, path)
+	}
+	return nil
+}
+
+// Lookup returns the value from the process environment if set, otherwise
+// from the highest-precedence file that defines key.
+func (c *DotenvCascade) Lookup(key string) (string, bool) {
+	if value, ok := c.env.Lookup(key); ok {
+		return value, true
+	}
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// Origin reports where the value of key comes from: OriginEnvironment, or the
+// path of the file that defines it.
+func (c *DotenvCascade) Origin(key string) (string, bool) {
+	if _, ok := c.env.Lookup(key); ok {
+		return OriginEnvironment, true
+	}
+	origin, ok := c.origins[key]
+	return origin, ok
+}
+
+// Profile returns the resolved profile, empty when none was set.
+func (c *DotenvCascade) Profile() string {
+	return c.profile
+}
+
+// Files returns the files that were read, from lowest to highest precedence.
+func (c *DotenvCascade) Files() []string {
+	return c.files
+}
@@ -0,0 +1,130 @@
+package env_config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeDotenvFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestNewDotenvCascade(t *testing.T) {
+	files := map[string]string{
+		".env":                       "CASCADE_A=env\nCASCADE_B=env\nCASCADE_C=env\nCASCADE_D=env\nCASCADE_E=env\n",
+		".env.local":                 "CASCADE_B=local\nCASCADE_C=local\nCASCADE_D=local\n",
+		".env.production":            "CASCADE_C=production\nCASCADE_D=production\n",
+		".env.production.local":      "CASCADE_D=production.local\n",
+		".env.staging":               "CASCADE_A=staging\n",
+		".env.production.local.skip": "CASCADE_A=ignored\n",
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		env         map[string]string
+		wantProfile string
+		wantFiles   []string
+		want        map[string][2]string // key -> value, origin
+	}{
+		{
+			name:        "profile from the environment",
+			files:       files,
+			env:         map[string]string{"CASCADE_APP_ENV": "production", "CASCADE_E": "os"},
+			wantProfile: "production",
+			wantFiles:   []string{".env", ".env.local", ".env.production", ".env.production.local"},
+			want: map[string][2]string{
+				"CASCADE_A": {"env", ".env"},
+				"CASCADE_B": {"local", ".env.local"},
+				"CASCADE_C": {"production", ".env.production"},
+				"CASCADE_D": {"production.local", ".env.production.local"},
+				"CASCADE_E": {"os", OriginEnvironment},
+			},
+		},
+		{
+			name:      "no profile",
+			files:     files,
+			wantFiles: []string{".env", ".env.local"},
+			want: map[string][2]string{
+				"CASCADE_C": {"local", ".env.local"},
+				"CASCADE_D": {"local", ".env.local"},
+			},
+		},
+		{
+			name: "profile from .env and missing files",
+			files: map[string]string{
+				".env":         "CASCADE_APP_ENV=staging\nCASCADE_A=env\n",
+				".env.staging": "CASCADE_A=staging\n",
+			},
+			wantProfile: "staging",
+			wantFiles:   []string{".env", ".env.staging"},
+			want: map[string][2]string{
+				"CASCADE_A": {"staging", ".env.staging"},
+			},
+		},
+		{
+			name: "process environment wins in expansions",
+			files: map[string]string{
+				".env": "CASCADE_HOST=file\nCASCADE_URL=http://${CASCADE_HOST}\n",
+			},
+			env:       map[string]string{"CASCADE_HOST": "os"},
+			wantFiles: []string{".env"},
+			want: map[string][2]string{
+				"CASCADE_HOST": {"os", OriginEnvironment},
+				"CASCADE_URL":  {"http://os", ".env"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			dir := writeDotenvFiles(t, tt.files)
+
+			source, err := NewDotenvCascade(dir, "CASCADE_APP_ENV")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantProfile, source.Profile())
+
+			var wantFiles []string
+			for _, name := range tt.wantFiles {
+				wantFiles = append(wantFiles, filepath.Join(dir, name))
+			}
+			assert.Equal(t, wantFiles, source.Files())
+
+			for key, want := range tt.want {
+				value, ok := source.Lookup(key)
+				assert.True(t, ok, key)
+				assert.Equal(t, want[0], value, key)
+
+				origin, ok := source.Origin(key)
+				assert.True(t, ok, key)
+				if want[1] != OriginEnvironment {
+					want[1] = filepath.Join(dir, want[1])
+				}
+				assert.Equal(t, want[1], origin, key)
+			}
+
+			_, ok := source.Lookup("CASCADE_MISSING")
+			assert.False(t, ok)
+			_, ok = source.Origin("CASCADE_MISSING")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestNewDotenvCascade_SyntaxError(t *testing.T) {
+	t.Setenv("CASCADE_APP_ENV", "test")
+	dir := writeDotenvFiles(t, map[string]string{".env.test": "OK=1\nBROKEN\n"})
+
+	_, err := NewDotenvCascade(dir, "CASCADE_APP_ENV")
+	assert.EqualError(t, err, "env_config: "+filepath.Join(dir, ".env.test")+":2: expected = after key BROKEN")
+}
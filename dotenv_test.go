@@ -0,0 +1,147 @@
+package env_config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDotenv(t *testing.T) {
+	os.Setenv("DOTENV_TEST_OS", "from-os")
+	defer os.Unsetenv("DOTENV_TEST_OS")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    MapSource
+		wantErr string
+	}{
+		{
+			name:  "simple values, comments and blank lines",
+			input: "# comment\n\nHOST=localhost\n  PORT = 8080  \nEMPTY=\n",
+			want:  MapSource{"HOST": "localhost", "PORT": "8080", "EMPTY": ""},
+		},
+		{
+			name:  "export prefix",
+			input: "export HOST=localhost\nexport\tPORT=8080",
+			want:  MapSource{"HOST": "localhost", "PORT": "8080"},
+		},
+		{
+			name:  "inline comment needs leading whitespace",
+			input: "URL=http://host/#anchor # comment\n",
+			want:  MapSource{"URL": "http://host/#anchor"},
+		},
+		{
+			name:  "single quotes are literal",
+			input: `RAW='a\nb ${HOST} # not a comment' # comment`,
+			want:  MapSource{"RAW": `a\nb ${HOST} # not a comment`},
+		},
+		{
+			name:  "double quote escapes",
+			input: `MSG="tab\tquote\" dollar\$ back\\ new\nline \q"`,
+			want:  MapSource{"MSG": "tab\tquote\" dollar$ back\\ new\nline \\q"},
+		},
+		{
+			name:  "multiline double-quoted value",
+			input: "CERT=\"-----BEGIN-----\nline\n-----END-----\"\nNEXT=1",
+			want:  MapSource{"CERT": "-----BEGIN-----\nline\n-----END-----", "NEXT": "1"},
+		},
+		{
+			name:  "interpolation from earlier keys and the environment",
+			input: "HOST=localhost\nURL=http://${HOST}:$PORT/$DOTENV_TEST_OS\nQUOTED=\"${HOST}\"",
+			want:  MapSource{"HOST": "localhost", "URL": "http://localhost:/from-os", "QUOTED": "localhost"},
+		},
+		{
+			name:  "interpolation fallback",
+			input: "EMPTY=\nA=${MISSING:-one}\nB=${EMPTY:-two}\nC=${DOTENV_TEST_OS:-three}",
+			want:  MapSource{"EMPTY": "", "A": "one", "B": "two", "C": "from-os"},
+		},
+		{
+			name:  "lone dollar is kept",
+			input: "PRICE=5$ and $\nBRACE=${open",
+			want:  MapSource{"PRICE": "5$ and $", "BRACE": "${open"},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "A=1\r\nB=\"2\"\r\n",
+			want:  MapSource{"A": "1", "B": "2"},
+		},
+		{
+			name:    "missing equals",
+			input:   "A=1\nINVALID\n",
+			wantErr: "env_config: line 2: expected = after key INVALID",
+		},
+		{
+			name:    "invalid key",
+			input:   "1KEY=value",
+			wantErr: `env_config: line 1: invalid key "1KEY=value"`,
+		},
+		{
+			name:    "unterminated double quote",
+			input:   "A=1\nB=\"open\n\n",
+			wantErr: "env_config: line 2: unterminated double-quoted value",
+		},
+		{
+			name:    "unterminated single quote",
+			input:   "A='open",
+			wantErr: "env_config: line 1: unterminated single-quoted value",
+		},
+		{
+			name:    "text after quoted value",
+			input:   "A=\"1\"2",
+			wantErr: `env_config: line 1: unexpected "2" after quoted value`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotenv(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				var syntaxErr *SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewDotenvSource(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	assert.NoError(t, os.WriteFile(base, []byte("HOST=localhost\nPORT=8080\n"), 0o600))
+	assert.NoError(t, os.WriteFile(local, []byte("PORT=9090\nURL=${HOST}:${PORT}\n"), 0o600))
+
+	source, err := NewDotenvSource(base, local)
+	assert.NoError(t, err)
+	assert.Equal(t, MapSource{"HOST": "localhost", "PORT": "9090", "URL": "localhost:9090"}, source)
+	_, inEnv := os.LookupEnv("HOST")
+	assert.False(t, inEnv, "the process environment must not change")
+
+	_, err = NewDotenvSource(filepath.Join(dir, "missing"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	bad := filepath.Join(dir, ".env.bad")
+	assert.NoError(t, os.WriteFile(bad, []byte("BROKEN\n"), 0o600))
+	_, err = NewDotenvSource(bad)
+	assert.EqualError(t, err, "env_config: "+bad+":1: expected = after key BROKEN")
+}
+
+func TestLoadConfig_DotenvSource(t *testing.T) {
+	source, err := ParseDotenv(strings.NewReader("export APP_HOST=\"db.local\"\nAPP_PORTS=80,443 # web\n"))
+	assert.NoError(t, err)
+
+	type Config struct {
+		Host  string `env:"HOST"`
+		Ports []int  `env:"PORTS"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithPrefix("APP")))
+	assert.Equal(t, Config{Host: "db.local", Ports: []int{80, 443}}, cfg)
+}
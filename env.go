@@ -1,9 +1,107 @@
 package env_config
 
-func LoadConfig(cfg interface{}) error {
-	root, err := NewStruct(cfg, "")
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// LoadConfig fills cfg, a pointer to a struct, using a Loader configured with
+// opts.
+func LoadConfig(cfg interface{}, opts ...Option) error {
+	return NewLoader(opts...).Load(cfg)
+}
+
+// LoadConfigContext is like LoadConfig but stops waiting for remote sources
+// when ctx is done. Sources and tag options implementing Prefetcher are
+// fetched in parallel first. When ctx ends before every key is resolved, the
+// returned *MultiError holds a *TimeoutError listing the unresolved keys.
+func LoadConfigContext(ctx context.Context, cfg interface{}, opts ...Option) error {
+	return NewLoader(opts...).LoadContext(ctx, cfg)
+}
+
+// Load allocates a T, loads it using a Loader configured with opts and
+// returns it. T must be a struct type or a pointer to one; Go generics cannot
+// enforce this at compile time, so any other T returns an error. On error the
+// zero T is returned.
+func Load[T any](opts ...Option) (T, error) {
+	var cfg T
+	typ := reflect.TypeFor[T]()
+
+	var target interface{}
+	switch {
+	case typ.Kind() == reflect.Struct:
+		target = &cfg
+	case typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct:
+		cfg = reflect.New(typ.Elem()).Interface().(T)
+		target = cfg
+	default:
+		return cfg, fmt.Errorf("env_config: cannot load %s, expected a struct or a pointer to a struct", typ)
+	}
+
+	if err := LoadConfig(target, opts...); err != nil {
+		var zero T
+		return zero, err
+	}
+	return cfg, nil
+}
+
+// MustLoad is like Load but panics on error. It is meant for main functions
+// where a broken configuration should stop the program.
+func MustLoad[T any](opts ...Option) T {
+	cfg, err := Load[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// Get reads a single value without a config struct, e.g.
+// Get[time.Duration]("TIMEOUT", "default=5s"). key and tag follow the same
+// rules as a struct field tagged `env:"<key>;<tag>"`, including the prefix
+// set with WithPrefix, so the value is parsed exactly as a field would be.
+func Get[T any](key, tag string, opts ...Option) (T, error) {
+	value, _, err := lookupValue[T](key, tag, opts)
+	return value, err
+}
+
+// Lookup is like Get without tag options. The boolean reports whether the
+// key was present in the Source.
+//
+// With WithReport, Get and Lookup replace the content of the report with the
+// single key they read, like a Load does with its fields.
+func Lookup[T any](key string, opts ...Option) (T, bool, error) {
+	return lookupValue[T](key, "", opts)
+}
+
+func lookupValue[T any](key, tag string, opts []Option) (T, bool, error) {
+	var value T
+
+	l := NewLoader(opts...)
+	if l.err != nil {
+		return value, false, l.err
+	}
+	l, publish := l.scopeReport()
+	defer publish()
+
+	tagOption, err := l.parseTag(tag)
+	if err != nil {
+		return value, false, &TagError{Path: key, Tag: tag, Err: err}
+	}
+
+	key = combineKeyPrefix(l.prefix, key)
+	item := FieldItem{
+		raw:       value,
+		key:       key,
+		path:      key,
+		value:     reflect.ValueOf(&value).Elem(),
+		tagOption: tagOption,
+		loader:    l,
+	}
+	present, err := item.load(context.Background())
 	if err != nil {
-		return err
+		var zero T
+		return zero, present, err
 	}
-	return root.Load()
+	return value, present, nil
 }
@@ -2,8 +2,11 @@ package env_config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -313,3 +316,327 @@ func toJSONStr(object interface{}) string {
 	data, _ := json.Marshal(object)
 	return string(data)
 }
+
+func TestLoadConfig_WithSource(t *testing.T) {
+	os.Setenv("SERVER_REDIS_HOST", "from-os")
+	defer os.Unsetenv("SERVER_REDIS_HOST")
+
+	cfg := &Config{}
+	err := LoadConfig(cfg, WithSource(MapSource{
+		"SERVER_REDIS_HOST":      "from-map",
+		"SERVER_REDIS_PORT":      "6380",
+		"APP_DB_HOST":            "db.internal",
+		"COMPLEX_STRING_ARRAY":   "a|b",
+		"NOT_SET_DEFAULT_STRING": "override",
+	}))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	assert.Equal(t, "from-map", cfg.ServerConfig.CacheConfig.Host)
+	assert.Equal(t, 6380, cfg.ServerConfig.CacheConfig.Port)
+	assert.Equal(t, "db.internal", cfg.NestedConfig.Database.Host)
+	assert.Equal(t, []string{"a", "b"}, cfg.ComplexConfig.StringArray)
+	assert.Equal(t, "override", cfg.NotSet.DefaultString)
+	assert.Equal(t, 10, cfg.NotSet.DefaultInt)
+}
+
+type EmptyValueConfig struct {
+	Name         string   `env:"NAME;default=fallback"`
+	NameUnset    string   `env:"NAME_UNSET;default=fallback"`
+	NameFallback string   `env:"NAME_FALLBACK;default=fallback;emptyIsUnset"`
+	Port         int      `env:"PORT;default=8080"`
+	PortFallback int      `env:"PORT_FALLBACK;default=8080;emptyIsUnset"`
+	Hosts        []string `env:"HOSTS;default=a,b"`
+	HostsUnset   []string `env:"HOSTS_UNSET;default=a,b;emptyIsUnset"`
+	Ports        []int    `env:"PORTS;default=80,443"`
+}
+
+func TestLoadConfig_EmptyValues(t *testing.T) {
+	cfg := &EmptyValueConfig{}
+	err := LoadConfig(cfg, WithSource(MapSource{
+		"NAME":          "",
+		"NAME_FALLBACK": "",
+		"PORT":          "",
+		"PORT_FALLBACK": "",
+		"HOSTS":         "",
+		"HOSTS_UNSET":   "",
+	}))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	assert.Equal(t, &EmptyValueConfig{
+		Name:         "",
+		NameUnset:    "fallback",
+		NameFallback: "fallback",
+		Port:         0,
+		PortFallback: 8080,
+		Hosts:        nil,
+		HostsUnset:   []string{"a", "b"},
+		Ports:        []int{80, 443},
+	}, cfg)
+}
+
+type RequiredConfig struct {
+	Host     string         `env:"HOST;required"`
+	Port     int            `env:"PORT;required;default=8080"`
+	User     string         `env:"USER;notEmpty"`
+	Password string         `env:"PASSWORD;required"`
+	Database *RequiredChild `env:"DB"`
+}
+
+type RequiredChild struct {
+	Name string `env:"NAME;required"`
+}
+
+func TestLoadConfig_Required(t *testing.T) {
+	cfg := &RequiredConfig{}
+	err := LoadConfig(cfg, WithSource(MapSource{
+		"USER":     "",
+		"PASSWORD": "",
+	}))
+	if err == nil {
+		t.Fatal("LoadConfig() expected error")
+	}
+
+	for _, key := range []string{"HOST", "USER", "DB_NAME"} {
+		assert.Contains(t, err.Error(), key)
+	}
+	assert.NotContains(t, err.Error(), "PASSWORD")
+	assert.NotContains(t, err.Error(), "PORT")
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+	assert.Equal(t, 8080, cfg.Port)
+}
+
+func TestLoadConfig_RequiredWithDefault(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT;required;default=8080"`
+	}
+	for _, strict := range []bool{false, true} {
+		cfg := &config{}
+		err := LoadConfig(cfg, WithSource(MapSource{}), WithStrict(strict))
+		assert.NoError(t, err, "strict=%v", strict)
+		assert.Equal(t, 8080, cfg.Port, "strict=%v", strict)
+	}
+}
+
+type ErrorConfig struct {
+	Database *ErrorDatabaseConfig `env:"DATABASE"`
+	Token    int                  `env:"TOKEN;sensitive"`
+	Labels   map[string]string    `env:"LABELS"`
+}
+
+type ErrorDatabaseConfig struct {
+	Host string `env:"HOST;required"`
+	Port int    `env:"PORT"`
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	err := LoadConfig(&ErrorConfig{}, WithSource(MapSource{
+		"DATABASE_PORT": "abc",
+		"TOKEN":         "secret",
+	}))
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("LoadConfig() error = %v, want *MultiError", err)
+	}
+	assert.Len(t, multi.Errors, 4)
+
+	var missing *MissingError
+	assert.True(t, errors.As(multi.Errors[0], &missing))
+	assert.Equal(t, &MissingError{Path: "ErrorConfig.Database.Host", Key: "DATABASE_HOST"}, missing)
+
+	var parseErr *ParseError
+	assert.True(t, errors.As(multi.Errors[1], &parseErr))
+	assert.Equal(t, "ErrorConfig.Database.Port", parseErr.Path)
+	assert.Equal(t, "DATABASE_PORT", parseErr.Key)
+	assert.Equal(t, "abc", parseErr.Value)
+	assert.Equal(t, reflect.TypeOf(0), parseErr.Type)
+	assert.ErrorIs(t, parseErr, strconv.ErrSyntax)
+
+	assert.True(t, errors.As(multi.Errors[2], &parseErr))
+	assert.True(t, parseErr.Sensitive)
+	assert.NotContains(t, parseErr.Error(), "secret")
+
+	var unsupported *UnsupportedTypeError
+	assert.True(t, errors.As(multi.Errors[3], &unsupported))
+	assert.Equal(t, "ErrorConfig.Labels", unsupported.Path)
+}
+
+func TestLoadConfig_Bytes(t *testing.T) {
+	cfg := &struct {
+		List []uint8  `env:"LIST"`
+		Raw  RawBytes `env:"RAW;oneof=1,2,3"`
+	}{}
+	err := LoadConfig(cfg, WithSource(MapSource{
+		"LIST": "1,2,3",
+		"RAW":  "1,2,3",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []uint8{1, 2, 3}, cfg.List)
+	assert.Equal(t, RawBytes("1,2,3"), cfg.Raw)
+
+	err = LoadConfig(cfg, WithSource(MapSource{"LIST": "secret"}))
+	var elementErr *ElementError
+	assert.ErrorAs(t, err, &elementErr)
+}
+
+func TestLoadConfig_SensitiveSlice(t *testing.T) {
+	cfg := &struct {
+		Pins  []int  `env:"PINS;sensitive"`
+		Codes []int8 `env:"CODES;sensitive"`
+	}{}
+	err := LoadConfig(cfg, WithSource(MapSource{
+		"PINS":  "1234,secret99",
+		"CODES": "1,98765",
+	}))
+
+	var elementErr *ElementError
+	if !errors.As(err, &elementErr) {
+		t.Fatalf("LoadConfig() error = %v, want *ElementError", err)
+	}
+	assert.True(t, elementErr.Sensitive)
+	assert.Contains(t, err.Error(), `element 1 ("******")`)
+	assert.NotContains(t, err.Error(), "secret99")
+	assert.NotContains(t, err.Error(), "1234")
+	assert.NotContains(t, err.Error(), "98765")
+}
+
+func TestLoadConfig_RangeError(t *testing.T) {
+	cfg := &struct {
+		Level int8 `env:"LEVEL"`
+	}{}
+	err := LoadConfig(cfg, WithSource(MapSource{"LEVEL": "300"}))
+
+	var rangeErr *RangeError
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("LoadConfig() error = %v, want *RangeError", err)
+	}
+	assert.Equal(t, "LEVEL", rangeErr.Key)
+	assert.Equal(t, "-128", rangeErr.Min)
+	assert.Equal(t, "127", rangeErr.Max)
+	assert.Equal(t, int8(0), cfg.Level)
+}
+
+func TestLoadConfig_DefaultParseError(t *testing.T) {
+	cfg := &struct {
+		Port int `env:"PORT;default=http"`
+	}{}
+	err := LoadConfig(cfg, WithSource(MapSource{}))
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("LoadConfig() error = %v, want *ParseError", err)
+	}
+	assert.Equal(t, "http", parseErr.Value)
+}
+
+func TestLoad(t *testing.T) {
+	source := WithSource(MapSource{"REDIS_HOST": "redis", "REDIS_PORT": "6380"})
+
+	t.Run("struct", func(t *testing.T) {
+		cfg, err := Load[ServerConfig](source)
+		assert.NoError(t, err)
+		assert.Equal(t, ServerConfig{CacheConfig: &RedisConfig{Host: "redis", Port: 6380}}, cfg)
+	})
+
+	t.Run("pointer to struct", func(t *testing.T) {
+		cfg, err := Load[*ServerConfig](source)
+		assert.NoError(t, err)
+		assert.Equal(t, &ServerConfig{CacheConfig: &RedisConfig{Host: "redis", Port: 6380}}, cfg)
+	})
+
+	t.Run("non-struct", func(t *testing.T) {
+		_, err := Load[int](source)
+		assert.EqualError(t, err, "env_config: cannot load int, expected a struct or a pointer to a struct")
+
+		_, err = Load[*string](source)
+		assert.Error(t, err)
+	})
+
+	t.Run("load error returns zero value", func(t *testing.T) {
+		cfg, err := Load[*ServerConfig](WithSource(MapSource{"REDIS_PORT": "abc"}))
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Nil(t, cfg)
+	})
+}
+
+func TestMustLoad(t *testing.T) {
+	cfg := MustLoad[RedisConfig](WithSource(MapSource{"HOST": "redis"}))
+	assert.Equal(t, RedisConfig{Host: "redis"}, cfg)
+
+	assert.Panics(t, func() {
+		MustLoad[RedisConfig](WithSource(MapSource{"PORT": "abc"}))
+	})
+	assert.Panics(t, func() {
+		MustLoad[[]string]()
+	})
+}
+
+func TestGet(t *testing.T) {
+	source := WithSource(MapSource{
+		"TIMEOUT":  "10s",
+		"PORTS":    "80|443",
+		"APP_NAME": "app",
+		"LEVEL":    "300",
+	})
+
+	timeout, err := Get[time.Duration]("TIMEOUT", "default=5s", source)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, timeout)
+
+	timeout, err = Get[time.Duration]("MISSING", "default=5s", source)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	ports, err := Get[[]int]("PORTS", "delimiter=|", source)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+
+	name, err := Get[*string]("NAME", "", source, WithPrefix("APP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app", *name)
+
+	_, err = Get[string]("MISSING", "required", source)
+	var missing *MissingError
+	assert.True(t, errors.As(err, &missing))
+
+	level, err := Get[int8]("LEVEL", "", source)
+	var rangeErr *RangeError
+	assert.True(t, errors.As(err, &rangeErr))
+	assert.Equal(t, int8(0), level)
+
+	_, err = Get[string]("NAME", "defualt=x", source)
+	var tagErr *TagError
+	assert.True(t, errors.As(err, &tagErr))
+
+	_, err = Get[map[string]string]("NAME", "", source)
+	var unsupported *UnsupportedTypeError
+	assert.True(t, errors.As(err, &unsupported))
+}
+
+func TestLookup(t *testing.T) {
+	source := WithSource(MapSource{"PORT": "8080", "EMPTY": ""})
+
+	port, present, err := Lookup[int]("PORT", source)
+	assert.NoError(t, err)
+	assert.True(t, present)
+	assert.Equal(t, 8080, port)
+
+	empty, present, err := Lookup[string]("EMPTY", source)
+	assert.NoError(t, err)
+	assert.True(t, present)
+	assert.Equal(t, "", empty)
+
+	missing, present, err := Lookup[int]("MISSING", source)
+	assert.NoError(t, err)
+	assert.False(t, present)
+	assert.Equal(t, 0, missing)
+
+	_, present, err = Lookup[bool]("PORT", source)
+	assert.True(t, present)
+	assert.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package env_config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces raw values of fields tagged with sensitive in error
+// messages.
+const redacted = "******"
+
+var (
+	_ error = &ParseError{}
+	_ error = &MissingError{}
+	_ error = &UnsupportedTypeError{}
+	_ error = &MultiError{}
+	_ error = &ElementError{}
+	_ error = &RangeError{}
+	_ error = &ValidationError{}
+	_ error = &TagError{}
+	_ error = &SyntaxError{}
+	_ error = &SourceError{}
+	_ error = &FetchError{}
+	_ error = &TimeoutError{}
+)
+
+// ParseError reports a value that could not be converted to the type of its
+// field.
+type ParseError struct {
+	// Path is the Go field path, e.g. Config.Database.Port.
+	Path string
+	// Key is the key looked up in the Source.
+	Key string
+	// Value is the raw value. Use Redacted to print it.
+	Value string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Sensitive is set for fields tagged with sensitive.
+	Sensitive bool
+	// Err is the underlying conversion error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	cause := e.Err.Error()
+	if e.Sensitive {
+		cause = redactCause(e.Err, e.Value)
+	}
+	return fmt.Sprintf("env_config: cannot parse %s=%q into %s (%s): %s", e.Key, e.Redacted(), e.Path, e.Type, cause)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Redacted returns the raw value, or a placeholder when the field is
+// sensitive.
+func (e *ParseError) Redacted() string {
+	if e.Sensitive {
+		return redacted
+	}
+	return e.Value
+}
+
+// redactCause describes err without the raw value: element and range errors
+// are printed redacted, strconv errors keep only the reason.
+func redactCause(err error, value string) string {
+	var elementErr *ElementError
+	if errors.As(err, &elementErr) {
+		element := *elementErr
+		element.Sensitive = true
+		return element.Error()
+	}
+	var rangeErr *RangeError
+	if errors.As(err, &rangeErr) {
+		rangeCopy := *rangeErr
+		rangeCopy.Sensitive = true
+		return rangeCopy.Error()
+	}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Func + ": " + numErr.Err.Error()
+	}
+	// Other conversion errors, e.g. from time.ParseDuration, quote the input.
+	return strings.ReplaceAll(err.Error(), strconv.Quote(value), strconv.Quote(redacted))
+}
+
+// MissingError reports a required key that is not set, or a notEmpty key
+// that is not set or empty.
+type MissingError struct {
+	Path string
+	Key  string
+	// Empty is set when the key is present but empty.
+	Empty bool
+}
+
+func (e *MissingError) Error() string {
+	if e.Empty {
+		return fmt.Sprintf("env_config: key %s (%s) must not be empty", e.Key, e.Path)
+	}
+	return fmt.Sprintf("env_config: required key %s (%s) is not set", e.Key, e.Path)
+}
+
+// UnsupportedTypeError reports a tagged field whose type has no registered
+// TypeStrategy.
+type UnsupportedTypeError struct {
+	Path string
+	Key  string
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("env_config: unsupported type %s for key %s (%s)", e.Type, e.Key, e.Path)
+}
+
+// MultiError collects every error raised while loading a struct. It supports
+// errors.Is and errors.As against each of the collected errors.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// ElementError reports a slice element that could not be converted.
+type ElementError struct {
+	Index   int
+	Element string
+	// Sensitive is set for fields tagged with sensitive.
+	Sensitive bool
+	Err       error
+}
+
+func (e *ElementError) Error() string {
+	if e.Sensitive {
+		return fmt.Sprintf("element %d (%q): %s", e.Index, redacted, redactCause(e.Err, e.Element))
+	}
+	return fmt.Sprintf("element %d (%q): %v", e.Index, e.Element, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// RangeError reports a number that does not fit the bit size of its target
+// type, e.g. LEVEL=300 for an int8 field.
+type RangeError struct {
+	// Key is filled in when the error is raised while loading a field.
+	Key   string
+	Value string
+	Type  reflect.Type
+	// Min and Max are the bounds allowed by Type.
+	Min string
+	Max string
+	// Sensitive is set for fields tagged with sensitive.
+	Sensitive bool
+	Err       error
+}
+
+func (e *RangeError) Error() string {
+	value := e.Value
+	if e.Sensitive {
+		value = redacted
+	}
+	return fmt.Sprintf("value %s out of range for %s, allowed [%s, %s]", value, e.Type, e.Min, e.Max)
+}
+
+func (e *RangeError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError reports a converted value rejected by a validation tag
+// option such as min= or oneof=. Err is set when the rule itself is invalid,
+// e.g. min=abc or a pattern that does not compile.
+type ValidationError struct {
+	Path      string
+	Key       string
+	Rule      string
+	Param     string
+	Value     string
+	Sensitive bool
+	Err       error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("env_config: invalid rule %s=%s for %s (%s): %v", e.Rule, e.Param, e.Key, e.Path, e.Err)
+	}
+	return fmt.Sprintf("env_config: %s (%s) value %q does not satisfy %s=%s", e.Key, e.Path, e.Redacted(), e.Rule, e.Param)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Redacted returns the value, or a placeholder when the field is sensitive.
+func (e *ValidationError) Redacted() string {
+	if e.Sensitive {
+		return redacted
+	}
+	return e.Value
+}
+
+// TagError reports a struct tag that cannot be parsed, such as one with an
+// unknown option name. It is returned by NewStruct and LoadConfig before
+// anything is loaded.
+type TagError struct {
+	// Path is the Go field path, e.g. Config.Database.Port.
+	Path string
+	// Tag is the full tag text.
+	Tag string
+	Err error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("env_config: invalid tag %q on %s: %v", e.Tag, e.Path, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// SyntaxError reports a malformed configuration file, such as a .env file.
+type SyntaxError struct {
+	// File is the path of the file, empty when parsing a reader.
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("env_config: line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("env_config: %s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// SourceError reports a key whose lookup failed, for instance because a
+// remote secret store could not be reached.
+type SourceError struct {
+	Path string
+	Key  string
+	Err  error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("env_config: cannot look up key %s (%s): %v", e.Key, e.Path, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// FetchError reports a remote source, or a secret read by a tag option, that
+// could not be fetched during a Load, with the keys it left unresolved. It
+// is reported once instead of a *SourceError per key.
+type FetchError struct {
+	Keys []string
+	Err  error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("env_config: cannot resolve %s: %v", strings.Join(e.Keys, ", "), e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError reports the keys that were still unresolved when the context
+// given to LoadContext was done. It unwraps to the context error, so
+// errors.Is(err, context.DeadlineExceeded) holds.
+type TimeoutError struct {
+	Keys []string
+	Err  error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("env_config: %v before resolving %s", e.Err, strings.Join(e.Keys, ", "))
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
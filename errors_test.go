@@ -0,0 +1,133 @@
+package env_config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ParseError
+		want string
+	}{
+		{
+			name: "plain value",
+			err: &ParseError{
+				Path:  "Config.Database.Port",
+				Key:   "DATABASE_PORT",
+				Value: "abc",
+				Type:  reflect.TypeOf(0),
+				Err:   strconv.ErrSyntax,
+			},
+			want: `env_config: cannot parse DATABASE_PORT="abc" into Config.Database.Port (int): invalid syntax`,
+		},
+		{
+			name: "sensitive value",
+			err: &ParseError{
+				Path:      "Config.Token",
+				Key:       "TOKEN",
+				Value:     "secret",
+				Type:      reflect.TypeOf(0),
+				Sensitive: true,
+				Err:       strconv.ErrSyntax,
+			},
+			want: `env_config: cannot parse TOKEN="******" into Config.Token (int): invalid syntax`,
+		},
+		{
+			name: "sensitive value quoted by strconv",
+			err: &ParseError{
+				Path:      "Config.Token",
+				Key:       "TOKEN",
+				Value:     "secret",
+				Type:      reflect.TypeOf(0),
+				Sensitive: true,
+				Err:       &strconv.NumError{Func: "ParseInt", Num: "secret", Err: strconv.ErrSyntax},
+			},
+			want: `env_config: cannot parse TOKEN="******" into Config.Token (int): ParseInt: invalid syntax`,
+		},
+		{
+			name: "sensitive slice element",
+			err: &ParseError{
+				Path:      "Config.Pins",
+				Key:       "PINS",
+				Value:     "1234,secret99",
+				Type:      reflect.TypeOf([]int{}),
+				Sensitive: true,
+				Err: &ElementError{
+					Index:   1,
+					Element: "secret99",
+					Err:     &strconv.NumError{Func: "ParseInt", Num: "secret99", Err: strconv.ErrSyntax},
+				},
+			},
+			want: `env_config: cannot parse PINS="******" into Config.Pins ([]int): element 1 ("******"): ParseInt: invalid syntax`,
+		},
+		{
+			name: "sensitive value inside a word is kept",
+			err: &ParseError{
+				Path:      "Config.Flag",
+				Key:       "FLAG",
+				Value:     "in",
+				Type:      reflect.TypeOf(false),
+				Sensitive: true,
+				Err:       strconv.ErrSyntax,
+			},
+			want: `env_config: cannot parse FLAG="******" into Config.Flag (bool): invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.Error())
+			assert.ErrorIs(t, tt.err, strconv.ErrSyntax)
+		})
+	}
+}
+
+func TestMissingError_Error(t *testing.T) {
+	assert.Equal(t, "env_config: required key HOST (Config.Host) is not set",
+		(&MissingError{Path: "Config.Host", Key: "HOST"}).Error())
+	assert.Equal(t, "env_config: key HOST (Config.Host) must not be empty",
+		(&MissingError{Path: "Config.Host", Key: "HOST", Empty: true}).Error())
+}
+
+func TestSyntaxError_Error(t *testing.T) {
+	assert.Equal(t, "env_config: line 3: expected =",
+		(&SyntaxError{Line: 3, Msg: "expected ="}).Error())
+	assert.Equal(t, "env_config: .env:3: expected =",
+		(&SyntaxError{File: ".env", Line: 3, Msg: "expected ="}).Error())
+}
+
+func TestSourceError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := &SourceError{Path: "Config.Token", Key: "TOKEN", Err: cause}
+	assert.Equal(t, "env_config: cannot look up key TOKEN (Config.Token): connection refused", err.Error())
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := &TimeoutError{Keys: []string{"TOKEN", "KEY"}, Err: context.DeadlineExceeded}
+	assert.Equal(t, "env_config: context deadline exceeded before resolving TOKEN, KEY", err.Error())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMultiError(t *testing.T) {
+	missing := &MissingError{Path: "Config.Host", Key: "HOST"}
+	parse := &ParseError{Path: "Config.Port", Key: "PORT", Value: "x", Type: reflect.TypeOf(0), Err: strconv.ErrSyntax}
+	err := error(&MultiError{Errors: []error{missing, parse}})
+
+	var gotMissing *MissingError
+	assert.True(t, errors.As(err, &gotMissing))
+	assert.Equal(t, missing, gotMissing)
+
+	var gotParse *ParseError
+	assert.True(t, errors.As(err, &gotParse))
+	assert.Equal(t, parse, gotParse)
+
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.Equal(t, missing.Error()+"\n"+parse.Error(), err.Error())
+}
@@ -0,0 +1,154 @@
+package env_config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	_ FallibleSource = &EtcdSource{}
+	_ OriginSource   = &EtcdSource{}
+	_ ContextSource  = &EtcdSource{}
+	_ Prefetcher     = &EtcdSource{}
+)
+
+// EtcdSource serves the keys stored under a prefix of etcd, read through the
+// v3 JSON gateway (/v3/kv/range) on first use. Key paths map to keys like
+// for ConsulSource, so /app/database/host serves APP_DATABASE_HOST, with
+// etcd:/app/database/host as its origin.
+type EtcdSource struct {
+	remoteValues
+
+	address    string
+	prefix     string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// EtcdOption configures an EtcdSource.
+type EtcdOption func(*EtcdSource)
+
+// WithEtcdAuth authenticates with etcd's user and password authentication.
+func WithEtcdAuth(username, password string) EtcdOption {
+	return func(s *EtcdSource) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithEtcdHTTPClient sets the HTTP client, http.DefaultClient by default.
+func WithEtcdHTTPClient(client *http.Client) EtcdOption {
+	return func(s *EtcdSource) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// NewEtcdSource creates a source for the keys under prefix on the etcd
+// server at address, e.g. http://127.0.0.1:2379.
+func NewEtcdSource(address, prefix string, opts ...EtcdOption) (*EtcdSource, error) {
+	if address == "" {
+		return nil, errors.New("env_config: etcd address is not set")
+	}
+	s := &EtcdSource{
+		address:    strings.TrimRight(address, "/"),
+		prefix:     prefix,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.remoteValues.fetch = s.fetch
+	return s, nil
+}
+
+func (s *EtcdSource) fetch(ctx context.Context) (MapSource, map[string]string, error) {
+	token, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	in := map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(s.prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(etcdPrefixEnd(s.prefix)),
+	}
+	var out struct {
+		Kvs []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := s.post(ctx, "/v3/kv/range", token, in, &out); err != nil {
+		return nil, nil, fmt.Errorf("etcd: range %s: %w", s.prefix, err)
+	}
+
+	values, origins := MapSource{}, map[string]string{}
+	for _, kv := range out.Kvs {
+		name, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("etcd: key %q: %w", kv.Key, err)
+		}
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("etcd: key %s: %w", name, err)
+		}
+		key := pathKey(string(name), "/")
+		values[key] = string(value)
+		origins[key] = "etcd:" + string(name)
+	}
+	return values, origins, nil
+}
+
+// authenticate returns a token when user authentication is configured.
+func (s *EtcdSource) authenticate(ctx context.Context) (string, error) {
+	if s.username == "" {
+		return "", nil
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	in := map[string]string{"name": s.username, "password": s.password}
+	if err := s.post(ctx, "/v3/auth/authenticate", "", in, &out); err != nil {
+		return "", fmt.Errorf("etcd: authenticate: %w", err)
+	}
+	return out.Token, nil
+}
+
+func (s *EtcdSource) post(ctx context.Context, path, token string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	_, err = doJSON(s.httpClient, req, out)
+	return err
+}
+
+// etcdPrefixEnd returns the range end matching every key starting with
+// prefix: the prefix with its last byte below 0xff incremented.
+func etcdPrefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// Every byte is 0xff, or the prefix is empty: read to the end.
+	return []byte{0}
+}
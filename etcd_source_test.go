@@ -0,0 +1,145 @@
+package env_config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEtcd implements the range and authenticate endpoints of the etcd v3
+// JSON gateway.
+type fakeEtcd struct {
+	t        *testing.T
+	kv       map[string]string
+	password string
+}
+
+func newFakeEtcd(t *testing.T) *httptest.Server {
+	e := &fakeEtcd{
+		t: t,
+		kv: map[string]string{
+			"/app/database/host": "db.internal",
+			"/app/database/port": "5432",
+			"/app/log-level":     "debug",
+			"/apps/other":        "ignored",
+		},
+	}
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (e *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var in map[string]string
+	assert.NoError(e.t, json.NewDecoder(r.Body).Decode(&in))
+
+	switch r.URL.Path {
+	case "/v3/auth/authenticate":
+		if in["name"] != "root" || in["password"] != "pass" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "etcdserver: authentication failed, invalid user ID or password", "code": 3})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": "etcd-token"})
+	case "/v3/kv/range":
+		if e.password != "" && r.Header.Get("Authorization") != "etcd-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "etcdserver: user name is empty", "code": 16})
+			return
+		}
+		start, _ := base64.StdEncoding.DecodeString(in["key"])
+		end, _ := base64.StdEncoding.DecodeString(in["range_end"])
+		var keys []string
+		for key := range e.kv {
+			if key >= string(start) && key < string(end) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		var kvs []map[string]string
+		for _, key := range keys {
+			kvs = append(kvs, map[string]string{
+				"key":   base64.StdEncoding.EncodeToString([]byte(key)),
+				"value": base64.StdEncoding.EncodeToString([]byte(e.kv[key])),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"header": map[string]string{"revision": "7"}, "kvs": kvs, "count": len(kvs)})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEtcdSource(t *testing.T) {
+	server := newFakeEtcd(t)
+
+	source, err := NewEtcdSource(server.URL+"/", "/app/")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		key         string
+		wantValue   string
+		wantOrigin  string
+		wantPresent bool
+	}{
+		{key: "APP_DATABASE_HOST", wantValue: "db.internal", wantOrigin: "etcd:/app/database/host", wantPresent: true},
+		{key: "APP_LOG_LEVEL", wantValue: "debug", wantOrigin: "etcd:/app/log-level", wantPresent: true},
+		{key: "APPS_OTHER", wantPresent: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, present, err := source.LookupErr(tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantPresent, present)
+
+			origin, present := source.Origin(tt.key)
+			assert.Equal(t, tt.wantOrigin, origin)
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+
+	type Config struct {
+		Host string `env:"DATABASE_HOST"`
+		Port int    `env:"DATABASE_PORT"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithPrefix("APP")))
+	assert.Equal(t, Config{Host: "db.internal", Port: 5432}, cfg)
+}
+
+func TestEtcdSource_Auth(t *testing.T) {
+	server := newFakeEtcd(t)
+	server.Config.Handler.(*fakeEtcd).password = "pass"
+
+	source, err := NewEtcdSource(server.URL, "/app", WithEtcdAuth("root", "pass"), WithEtcdHTTPClient(server.Client()))
+	assert.NoError(t, err)
+	value, ok, err := source.LookupErr("APP_DATABASE_PORT")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "5432", value)
+
+	source, err = NewEtcdSource(server.URL, "/app", WithEtcdAuth("root", "wrong"))
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("APP_DATABASE_PORT")
+	assert.EqualError(t, err, `etcd: authenticate: 400 Bad Request: {"code":3,"error":"etcdserver: authentication failed, invalid user ID or password"}`)
+
+	source, err = NewEtcdSource(server.URL, "/app")
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("APP_DATABASE_PORT")
+	assert.ErrorContains(t, err, "etcd: range /app: 401 Unauthorized")
+
+	_, err = NewEtcdSource("", "/app")
+	assert.EqualError(t, err, "env_config: etcd address is not set")
+}
+
+func TestEtcdPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("/app0"), etcdPrefixEnd("/app/"))
+	assert.Equal(t, []byte("b"), etcdPrefixEnd("a\xff"))
+	assert.Equal(t, []byte{0}, etcdPrefixEnd("\xff"))
+	assert.Equal(t, []byte{0}, etcdPrefixEnd(""))
+}
@@ -4,26 +4,29 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/joho/godotenv/autoload"
-
 	"github.com/trinhdaiphuc/env_config"
 )
 
 type Config struct {
-	Host      string        `env:"HOST;default=localhost"`
-	Port      int           `env:"PORT;default=8080"`
-	Bytes     []byte        `env:"ENV_BYTES;default=foo,bar"`
-	Float32   float32       `env:"ENV_FLOAT;default=12.34"`
-	Timeout   time.Duration `env:"TIMEOUT"`
-	Date      time.Time     `env:"ENV_DATE"`
-	Addresses []string      `env:"ADDRESSES;delimiter= "`
-	Strings   []string      `env:"STRINGS;default=foo,bar"`
-	None      string        `env:"NONE;default=none"`
+	Host      string              `env:"HOST;default=localhost"`
+	Port      int                 `env:"PORT;default=8080"`
+	Bytes     env_config.RawBytes `env:"ENV_BYTES;default=foo,bar"`
+	Float32   float32             `env:"ENV_FLOAT;default=12.34"`
+	Timeout   time.Duration       `env:"TIMEOUT"`
+	Date      time.Time           `env:"ENV_DATE"`
+	Addresses []string            `env:"ADDRESSES;delimiter= "`
+	Strings   []string            `env:"STRINGS;default=foo,bar"`
+	None      string              `env:"NONE;default=none"`
 }
 
 func main() {
+	source, err := env_config.NewDotenvSource(".env")
+	if err != nil {
+		panic(err)
+	}
+
 	cfg := &Config{}
-	if err := env_config.LoadConfig(cfg); err != nil {
+	if err := env_config.LoadConfig(cfg, env_config.WithSource(source)); err != nil {
 		panic(err)
 	}
 	fmt.Printf("Config %+v\n", cfg)
@@ -2,7 +2,8 @@ module github.com/trinhdaiphuc/env_config/example
 
 go 1.22.0
 
-require (
-	github.com/joho/godotenv v1.5.1
-	github.com/trinhdaiphuc/env_config v0.2.1
-)
+require github.com/trinhdaiphuc/env_config v0.2.1
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace github.com/trinhdaiphuc/env_config => ../
@@ -0,0 +1,64 @@
+package env_config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flatten stores the leaves of a decoded document in out, naming each one
+// like combineKeyPrefix would: {"database": {"host": "x"}} becomes
+// DATABASE_HOST=x. Arrays of scalars are also joined with Comma under their
+// own key, so both PORTS=80,443 and PORTS_0=80, PORTS_1=443 are set. Values
+// must be maps with string keys, []interface{} or scalars.
+func flatten(out MapSource, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			flatten(out, combineKeyPrefix(key, normalizeKey(name)), child)
+		}
+	case []interface{}:
+		joinable := true
+		elements := make([]string, 0, len(v))
+		for i, child := range v {
+			flatten(out, combineKeyPrefix(key, strconv.Itoa(i)), child)
+			switch child.(type) {
+			case map[string]interface{}, []interface{}:
+				joinable = false
+			default:
+				elements = append(elements, scalarString(child))
+			}
+		}
+		if joinable && key != "" {
+			out[key] = strings.Join(elements, Comma)
+		}
+	default:
+		if key != "" {
+			out[key] = scalarString(v)
+		}
+	}
+}
+
+func scalarString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// normalizeKey turns a document key such as "max-idle.conns" into the
+// environment style MAX_IDLE_CONNS.
+func normalizeKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,67 @@
+package env_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  MapSource
+	}{
+		{
+			name: "nested maps",
+			value: map[string]interface{}{
+				"port":     8080,
+				"database": map[string]interface{}{"host": "db", "max-idle": map[string]interface{}{"conns": true}},
+			},
+			want: MapSource{"PORT": "8080", "DATABASE_HOST": "db", "DATABASE_MAX_IDLE_CONNS": "true"},
+		},
+		{
+			name:  "scalar arrays are joined and indexed",
+			value: map[string]interface{}{"ports": []interface{}{80, "443"}},
+			want:  MapSource{"PORTS": "80,443", "PORTS_0": "80", "PORTS_1": "443"},
+		},
+		{
+			name: "arrays of maps are indexed only",
+			value: map[string]interface{}{"servers": []interface{}{
+				map[string]interface{}{"host": "a"},
+				map[string]interface{}{"host": "b", "tags": []interface{}{"x"}},
+			}},
+			want: MapSource{"SERVERS_0_HOST": "a", "SERVERS_1_HOST": "b", "SERVERS_1_TAGS": "x", "SERVERS_1_TAGS_0": "x"},
+		},
+		{
+			name:  "empty array and null",
+			value: map[string]interface{}{"list": []interface{}{}, "none": nil},
+			want:  MapSource{"LIST": "", "NONE": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MapSource{}
+			flatten(got, "", tt.value)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "host", want: "HOST"},
+		{name: "maxIdle", want: "MAXIDLE"},
+		{name: "max-idle.conns", want: "MAX_IDLE_CONNS"},
+		{name: "API_key 2", want: "API_KEY_2"},
+		{name: "héllo", want: "H_LLO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeKey(tt.name))
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package env_config
 
-import "strconv"
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+)
 
 type FloatType interface {
 	float32 | float64
@@ -14,35 +19,122 @@ type UintType interface {
 	uint | uint8 | uint16 | uint32 | uint64
 }
 
-func StringArrayToFloatArray[F FloatType](strings []string) []F {
+// The StringArrayTo* helpers convert every element they can and leave the
+// zero value for the others. The returned error is an *ElementError for the
+// first element that failed, so callers that want the lenient behavior can
+// keep the values and ignore it.
+
+func StringArrayToFloatArray[F FloatType](strings []string) ([]F, error) {
+	var firstErr error
 	floats := make([]F, len(strings))
 	for i, s := range strings {
-		f, err := strconv.ParseFloat(s, 64)
-		if err == nil {
-			floats[i] = F(f)
+		f, err := parseFloat[F](s)
+		if err != nil {
+			firstErr = firstElementError(firstErr, i, s, err)
+			continue
 		}
+		floats[i] = f
 	}
-	return floats
+	return floats, firstErr
 }
 
-func StringArrayToIntArray[I IntType](strings []string) []I {
+func StringArrayToIntArray[I IntType](strings []string) ([]I, error) {
+	var firstErr error
 	ints := make([]I, len(strings))
 	for i, s := range strings {
-		n, err := strconv.Atoi(s)
-		if err == nil {
-			ints[i] = I(n)
+		n, err := parseInt[I](s)
+		if err != nil {
+			firstErr = firstElementError(firstErr, i, s, err)
+			continue
 		}
+		ints[i] = n
 	}
-	return ints
+	return ints, firstErr
 }
 
-func StringArrayToUintArray[U UintType](strings []string) []U {
+func StringArrayToUintArray[U UintType](strings []string) ([]U, error) {
+	var firstErr error
 	uints := make([]U, len(strings))
 	for i, s := range strings {
-		n, err := strconv.ParseUint(s, 10, 64)
-		if err == nil {
-			uints[i] = U(n)
+		n, err := parseUint[U](s)
+		if err != nil {
+			firstErr = firstElementError(firstErr, i, s, err)
+			continue
+		}
+		uints[i] = n
+	}
+	return uints, firstErr
+}
+
+func StringArrayToBoolArray(strings []string) ([]bool, error) {
+	var firstErr error
+	bools := make([]bool, len(strings))
+	for i, s := range strings {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			firstErr = firstElementError(firstErr, i, s, err)
+			continue
+		}
+		bools[i] = b
+	}
+	return bools, firstErr
+}
+
+func firstElementError(firstErr error, index int, element string, err error) error {
+	if firstErr != nil {
+		return firstErr
+	}
+	return &ElementError{Index: index, Element: element, Err: err}
+}
+
+// parseInt parses s using the bit size of I, so values that do not fit are
+// reported as a *RangeError instead of wrapping around.
+func parseInt[I IntType](s string) (I, error) {
+	typ := reflect.TypeFor[I]()
+	n, err := strconv.ParseInt(s, 10, typ.Bits())
+	if errors.Is(err, strconv.ErrRange) {
+		minValue := int64(-1) << (typ.Bits() - 1)
+		return 0, &RangeError{
+			Value: s,
+			Type:  typ,
+			Min:   strconv.FormatInt(minValue, 10),
+			Max:   strconv.FormatInt(-(minValue + 1), 10),
+			Err:   err,
+		}
+	}
+	return I(n), err
+}
+
+func parseUint[U UintType](s string) (U, error) {
+	typ := reflect.TypeFor[U]()
+	n, err := strconv.ParseUint(s, 10, typ.Bits())
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, &RangeError{
+			Value: s,
+			Type:  typ,
+			Min:   "0",
+			Max:   strconv.FormatUint(math.MaxUint64>>(64-typ.Bits()), 10),
+			Err:   err,
+		}
+	}
+	return U(n), err
+}
+
+func parseFloat[F FloatType](s string) (F, error) {
+	typ := reflect.TypeFor[F]()
+	f, err := strconv.ParseFloat(s, typ.Bits())
+	if errors.Is(err, strconv.ErrRange) {
+		maxValue := math.MaxFloat64
+		if typ.Bits() == 32 {
+			maxValue = math.MaxFloat32
+		}
+		return 0, &RangeError{
+			Value: s,
+			Type:  typ,
+			Min:   strconv.FormatFloat(-maxValue, 'g', -1, typ.Bits()),
+			Max:   strconv.FormatFloat(maxValue, 'g', -1, typ.Bits()),
+			Err:   err,
 		}
 	}
-	return uints
+	return F(f), err
 }
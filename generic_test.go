@@ -0,0 +1,236 @@
+package env_config
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestStringArrayToIntArray(t *testing.T) {
+	tests := []struct {
+		name      string
+		strings   []string
+		want      []int
+		wantIndex int
+		wantErr   bool
+	}{
+		{
+			name:    "valid elements",
+			strings: []string{"80", "-443"},
+			want:    []int{80, -443},
+		},
+		{
+			name:      "invalid element",
+			strings:   []string{"80", "abc", "x"},
+			want:      []int{80, 0, 0},
+			wantIndex: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StringArrayToIntArray[int](tt.strings)
+			assertElementError(t, err, tt.wantErr, tt.wantIndex, tt.strings)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StringArrayToIntArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringArrayToUintArray(t *testing.T) {
+	tests := []struct {
+		name      string
+		strings   []string
+		want      []uint
+		wantIndex int
+		wantErr   bool
+	}{
+		{
+			name:    "valid elements",
+			strings: []string{"80", "443"},
+			want:    []uint{80, 443},
+		},
+		{
+			name:      "negative element",
+			strings:   []string{"-1", "443"},
+			want:      []uint{0, 443},
+			wantIndex: 0,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StringArrayToUintArray[uint](tt.strings)
+			assertElementError(t, err, tt.wantErr, tt.wantIndex, tt.strings)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StringArrayToUintArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringArrayToFloatArray(t *testing.T) {
+	tests := []struct {
+		name      string
+		strings   []string
+		want      []float64
+		wantIndex int
+		wantErr   bool
+	}{
+		{
+			name:    "valid elements",
+			strings: []string{"1.5", "-2"},
+			want:    []float64{1.5, -2},
+		},
+		{
+			name:      "invalid element",
+			strings:   []string{"1.5", "pi"},
+			want:      []float64{1.5, 0},
+			wantIndex: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StringArrayToFloatArray[float64](tt.strings)
+			assertElementError(t, err, tt.wantErr, tt.wantIndex, tt.strings)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StringArrayToFloatArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringArrayToBoolArray(t *testing.T) {
+	tests := []struct {
+		name      string
+		strings   []string
+		want      []bool
+		wantIndex int
+		wantErr   bool
+	}{
+		{
+			name:    "valid elements",
+			strings: []string{"true", "0"},
+			want:    []bool{true, false},
+		},
+		{
+			name:      "invalid element",
+			strings:   []string{"true", "yes"},
+			want:      []bool{true, false},
+			wantIndex: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StringArrayToBoolArray(tt.strings)
+			assertElementError(t, err, tt.wantErr, tt.wantIndex, tt.strings)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StringArrayToBoolArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func assertElementError(t *testing.T, err error, wantErr bool, wantIndex int, strings []string) {
+	t.Helper()
+	if (err != nil) != wantErr {
+		t.Fatalf("error = %v, wantErr %v", err, wantErr)
+	}
+	if !wantErr {
+		return
+	}
+
+	var elemErr *ElementError
+	if !errors.As(err, &elemErr) {
+		t.Fatalf("error = %T, want *ElementError", err)
+	}
+	if elemErr.Index != wantIndex || elemErr.Element != strings[wantIndex] {
+		t.Errorf("ElementError = {%d %q}, want {%d %q}", elemErr.Index, elemErr.Element, wantIndex, strings[wantIndex])
+	}
+}
+
+func TestParseNumbers_Range(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) error
+		value   string
+		wantMin string
+		wantMax string
+	}{
+		{
+			name:    "int8 overflow",
+			parse:   func(s string) error { _, err := parseInt[int8](s); return err },
+			value:   "300",
+			wantMin: "-128",
+			wantMax: "127",
+		},
+		{
+			name:    "int16 underflow",
+			parse:   func(s string) error { _, err := parseInt[int16](s); return err },
+			value:   "-40000",
+			wantMin: "-32768",
+			wantMax: "32767",
+		},
+		{
+			name:    "int64 overflow",
+			parse:   func(s string) error { _, err := parseInt[int64](s); return err },
+			value:   "9223372036854775808",
+			wantMin: "-9223372036854775808",
+			wantMax: "9223372036854775807",
+		},
+		{
+			name:    "uint8 overflow",
+			parse:   func(s string) error { _, err := parseUint[uint8](s); return err },
+			value:   "256",
+			wantMin: "0",
+			wantMax: "255",
+		},
+		{
+			name:    "uint64 overflow",
+			parse:   func(s string) error { _, err := parseUint[uint64](s); return err },
+			value:   "18446744073709551616",
+			wantMin: "0",
+			wantMax: "18446744073709551615",
+		},
+		{
+			name:    "float32 overflow",
+			parse:   func(s string) error { _, err := parseFloat[float32](s); return err },
+			value:   "1e39",
+			wantMin: "-3.4028235e+38",
+			wantMax: "3.4028235e+38",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse(tt.value)
+
+			var rangeErr *RangeError
+			if !errors.As(err, &rangeErr) {
+				t.Fatalf("error = %v, want *RangeError", err)
+			}
+			if rangeErr.Value != tt.value || rangeErr.Min != tt.wantMin || rangeErr.Max != tt.wantMax {
+				t.Errorf("RangeError = %+v, want value %s in [%s, %s]", rangeErr, tt.value, tt.wantMin, tt.wantMax)
+			}
+			if !errors.Is(err, strconv.ErrRange) {
+				t.Errorf("error = %v, want strconv.ErrRange", err)
+			}
+		})
+	}
+}
+
+func TestStringArrayToIntArray_Range(t *testing.T) {
+	_, err := StringArrayToIntArray[int8]([]string{"1", "300"})
+
+	var elemErr *ElementError
+	var rangeErr *RangeError
+	if !errors.As(err, &elemErr) || !errors.As(err, &rangeErr) {
+		t.Fatalf("error = %v, want *ElementError wrapping *RangeError", err)
+	}
+	if elemErr.Index != 1 || rangeErr.Max != "127" {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package env_config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	_ FallibleSource = &HTTPSource{}
+	_ OriginSource   = &HTTPSource{}
+	_ ContextSource  = &HTTPSource{}
+	_ Prefetcher     = &HTTPSource{}
+)
+
+// HTTPSource serves a JSON document fetched from a URL, flattened like
+// NewJSONSource does. The document is fetched on first use; Refresh fetches
+// it again. Failed requests are retried with exponential backoff, and when
+// the service stays unreachable the last document saved to the cache file,
+// if any, is served instead. The origin of every key is the URL, or the cache
+// file in that case.
+type HTTPSource struct {
+	remoteValues
+
+	url        string
+	headers    http.Header
+	username   string
+	password   string
+	httpClient *http.Client
+	attempts   int
+	backoff    time.Duration
+	cacheFile  string
+
+	mu   sync.Mutex
+	etag string
+	body []byte
+}
+
+// HTTPOption configures an HTTPSource.
+type HTTPOption func(*HTTPSource)
+
+// WithHTTPBearerToken sends token in an Authorization: Bearer header.
+func WithHTTPBearerToken(token string) HTTPOption {
+	return func(s *HTTPSource) {
+		s.headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithHTTPBasicAuth authenticates with HTTP basic authentication.
+func WithHTTPBasicAuth(username, password string) HTTPOption {
+	return func(s *HTTPSource) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithHTTPHeader adds a header to every request, e.g. an API key.
+func WithHTTPHeader(name, value string) HTTPOption {
+	return func(s *HTTPSource) {
+		s.headers.Add(name, value)
+	}
+}
+
+// WithHTTPClient sets the HTTP client, http.DefaultClient by default. Set
+// its Timeout to bound each attempt.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(s *HTTPSource) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// WithHTTPRetries sets how many times a request is attempted, 3 by default,
+// and the delay before the first retry, 500ms by default, doubled after each
+// attempt. Network errors, 429 and 5xx responses are retried.
+func WithHTTPRetries(attempts int, backoff time.Duration) HTTPOption {
+	return func(s *HTTPSource) {
+		if attempts < 1 {
+			attempts = 1
+		}
+		s.attempts = attempts
+		s.backoff = backoff
+	}
+}
+
+// WithHTTPCacheFile keeps the last document and its ETag in path. The cache
+// is read when the source is created, so the first request can be
+// conditional, and is served when the service cannot be reached.
+func WithHTTPCacheFile(path string) HTTPOption {
+	return func(s *HTTPSource) {
+		s.cacheFile = path
+	}
+}
+
+// httpCache is the content of the cache file.
+type httpCache struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// NewHTTPSource creates a source for the JSON document at url.
+func NewHTTPSource(url string, opts ...HTTPOption) (*HTTPSource, error) {
+	if url == "" {
+		return nil, errors.New("env_config: http source url is not set")
+	}
+	s := &HTTPSource{
+		url:        url,
+		headers:    http.Header{},
+		httpClient: http.DefaultClient,
+		attempts:   3,
+		backoff:    500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.remoteValues.fetch = s.fetch
+
+	if s.cacheFile != "" {
+		data, err := os.ReadFile(s.cacheFile)
+		var cache httpCache
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, err
+		case json.Unmarshal(data, &cache) == nil:
+			s.etag, s.body = cache.ETag, cache.Body
+		}
+	}
+	return s, nil
+}
+
+// Refresh fetches the document again, sending the ETag of the current one so
+// an unchanged document is not transferred.
+func (s *HTTPSource) Refresh(ctx context.Context) error {
+	values, origins, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	s.remoteValues.set(values, origins)
+	return nil
+}
+
+func (s *HTTPSource) fetch(ctx context.Context) (MapSource, map[string]string, error) {
+	body, transient, err := s.download(ctx)
+	origin := s.url
+	if err != nil {
+		// Only an unreachable service falls back to the last document,
+		// even past the deadline of ctx; answers such as 401 or 404 point
+		// at a misconfiguration and are reported.
+		s.mu.Lock()
+		body = s.body
+		s.mu.Unlock()
+		if body == nil || !transient {
+			return nil, nil, fmt.Errorf("http source %s: %w", s.url, err)
+		}
+		if s.cacheFile != "" {
+			origin = s.cacheFile
+		}
+	}
+
+	values, err := ParseJSON(bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("http source %s: %w", origin, err)
+	}
+	origins := make(map[string]string, len(values))
+	for key := range values {
+		origins[key] = origin
+	}
+	return values, origins, nil
+}
+
+// download returns the current document, retrying failed attempts. On
+// failure it reports whether the last one was transient.
+func (s *HTTPSource) download(ctx context.Context) ([]byte, bool, error) {
+	delay := s.backoff
+	for attempt := 1; ; attempt++ {
+		body, transient, err := s.get(ctx)
+		if err == nil {
+			return body, false, nil
+		}
+		if !transient || attempt >= s.attempts {
+			return nil, transient, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, true, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// get performs a single conditional request, reporting whether a failure is
+// transient: a network error, 429 or 5xx, worth retrying.
+func (s *HTTPSource) get(ctx context.Context) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for name, values := range s.headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.username != "" || s.password != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	s.mu.Lock()
+	etag, cached := s.etag, s.body
+	s.mu.Unlock()
+	if etag != "" && cached != nil {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, true, errors.New(resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, errors.New(resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	if _, err := ParseJSON(bytes.NewReader(body)); err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	s.etag, s.body = resp.Header.Get("ETag"), body
+	s.mu.Unlock()
+	// The cache is a fallback: failing to write it must not fail loading.
+	_ = s.saveCache(resp.Header.Get("ETag"), body)
+	return body, false, nil
+}
+
+// saveCache writes the cache file atomically, so a crash cannot leave a
+// truncated cache behind.
+func (s *HTTPSource) saveCache(etag string, body []byte) error {
+	if s.cacheFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(httpCache{ETag: etag, Body: body})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.cacheFile), filepath.Base(s.cacheFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.cacheFile)
+}
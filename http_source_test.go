@@ -0,0 +1,331 @@
+package env_config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConfigService serves a JSON document with an ETag, failing the first
+// failures requests with status.
+type fakeConfigService struct {
+	mu       sync.Mutex
+	document string
+	etag     string
+	failures int
+	status   int
+	requests []*http.Request
+}
+
+func newFakeConfigService(t *testing.T) (*fakeConfigService, *httptest.Server) {
+	f := &fakeConfigService{
+		document: `{"database": {"host": "db.internal", "port": 5432}, "hosts": ["a", "b"]}`,
+		etag:     `"v1"`,
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeConfigService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(f.status)
+		return
+	}
+	if r.Header.Get("If-None-Match") == f.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", f.etag)
+	w.Write([]byte(f.document))
+}
+
+func (f *fakeConfigService) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func TestHTTPSource(t *testing.T) {
+	fake, server := newFakeConfigService(t)
+
+	source, err := NewHTTPSource(server.URL,
+		WithHTTPBearerToken("token"),
+		WithHTTPHeader("X-Api-Key", "key"),
+		WithHTTPClient(server.Client()),
+	)
+	assert.NoError(t, err)
+
+	type Database struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	type Config struct {
+		Database Database `env:"DATABASE"`
+		Hosts    []string `env:"HOSTS"`
+	}
+	var (
+		cfg    Config
+		report Report
+	)
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithReport(&report)))
+	assert.Equal(t, Config{Database: Database{Host: "db.internal", Port: 5432}, Hosts: []string{"a", "b"}}, cfg)
+
+	field, _ := report.Field("Config.Database.Host")
+	assert.Equal(t, server.URL, field.Origin)
+
+	assert.Equal(t, 1, fake.requestCount())
+	req := fake.requests[0]
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "key", req.Header.Get("X-Api-Key"))
+	assert.Equal(t, "application/json", req.Header.Get("Accept"))
+	assert.Empty(t, req.Header.Get("If-None-Match"))
+}
+
+func TestHTTPSource_BasicAuth(t *testing.T) {
+	fake, server := newFakeConfigService(t)
+
+	source, err := NewHTTPSource(server.URL, WithHTTPBasicAuth("user", "pass"))
+	assert.NoError(t, err)
+	_, ok := source.Lookup("DATABASE_HOST")
+	assert.True(t, ok)
+
+	username, password, ok := fake.requests[0].BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "pass", password)
+}
+
+func TestHTTPSource_Refresh(t *testing.T) {
+	fake, server := newFakeConfigService(t)
+
+	source, err := NewHTTPSource(server.URL)
+	assert.NoError(t, err)
+	value, _ := source.Lookup("DATABASE_HOST")
+	assert.Equal(t, "db.internal", value)
+
+	// Unchanged: the server answers 304 and the values stay.
+	assert.NoError(t, source.Refresh(context.Background()))
+	assert.Equal(t, `"v1"`, fake.requests[1].Header.Get("If-None-Match"))
+	value, _ = source.Lookup("DATABASE_HOST")
+	assert.Equal(t, "db.internal", value)
+
+	fake.mu.Lock()
+	fake.document, fake.etag = `{"database": {"host": "db2.internal"}}`, `"v2"`
+	fake.mu.Unlock()
+	assert.NoError(t, source.Refresh(context.Background()))
+	value, _ = source.Lookup("DATABASE_HOST")
+	assert.Equal(t, "db2.internal", value)
+	_, ok := source.Lookup("DATABASE_PORT")
+	assert.False(t, ok)
+}
+
+func TestHTTPSource_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		attempts     int
+		wantErr      string
+		wantRequests int
+	}{
+		{
+			name:         "recovers after server errors",
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			attempts:     3,
+			wantRequests: 3,
+		},
+		{
+			name:         "retries rate limiting",
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			attempts:     2,
+			wantRequests: 2,
+		},
+		{
+			name:         "gives up after the last attempt",
+			failures:     5,
+			status:       http.StatusBadGateway,
+			attempts:     3,
+			wantErr:      "502 Bad Gateway",
+			wantRequests: 3,
+		},
+		{
+			name:         "client errors are not retried",
+			failures:     1,
+			status:       http.StatusUnauthorized,
+			attempts:     3,
+			wantErr:      "401 Unauthorized",
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server := newFakeConfigService(t)
+			fake.failures, fake.status = tt.failures, tt.status
+
+			source, err := NewHTTPSource(server.URL, WithHTTPRetries(tt.attempts, time.Millisecond))
+			assert.NoError(t, err)
+			value, ok, err := source.LookupErr("DATABASE_HOST")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, "http source "+server.URL+": "+tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, ok)
+				assert.Equal(t, "db.internal", value)
+			}
+			assert.Equal(t, tt.wantRequests, fake.requestCount())
+		})
+	}
+}
+
+func TestHTTPSource_LoadFailure(t *testing.T) {
+	fake, server := newFakeConfigService(t)
+	fake.failures, fake.status = 100, http.StatusServiceUnavailable
+	source, err := NewHTTPSource(server.URL, WithHTTPRetries(3, time.Millisecond))
+	assert.NoError(t, err)
+
+	type Database struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	type Config struct {
+		Database Database `env:"DATABASE"`
+		Hosts    []string `env:"HOSTS"`
+		Name     string   `env:"NAME;default=app"`
+	}
+	err = LoadConfig(&Config{}, WithSource(source))
+
+	// The fields share the prefetch and its retries, and the failure is
+	// reported once.
+	assert.Equal(t, 3, fake.requestCount())
+	var multi *MultiError
+	if assert.ErrorAs(t, err, &multi) && assert.Len(t, multi.Errors, 1) {
+		var fetchErr *FetchError
+		assert.ErrorAs(t, multi.Errors[0], &fetchErr)
+		assert.Equal(t, []string{"DATABASE_HOST", "DATABASE_PORT", "HOSTS", "NAME"}, fetchErr.Keys)
+	}
+
+	// The next Load tries again.
+	fake.mu.Lock()
+	fake.failures = 0
+	fake.mu.Unlock()
+	assert.NoError(t, LoadConfig(&Config{}, WithSource(source)))
+	assert.Equal(t, 4, fake.requestCount())
+}
+
+func TestHTTPSource_CacheFile(t *testing.T) {
+	fake, server := newFakeConfigService(t)
+	cacheFile := filepath.Join(t.TempDir(), "config-cache.json")
+
+	// The first boot fills the cache.
+	source, err := NewHTTPSource(server.URL, WithHTTPCacheFile(cacheFile))
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("DATABASE_HOST")
+	assert.NoError(t, err)
+	assert.FileExists(t, cacheFile)
+
+	// The next boot sends the cached ETag and reuses the cached document.
+	source, err = NewHTTPSource(server.URL, WithHTTPCacheFile(cacheFile))
+	assert.NoError(t, err)
+	value, _, err := source.LookupErr("DATABASE_PORT")
+	assert.NoError(t, err)
+	assert.Equal(t, "5432", value)
+	assert.Equal(t, `"v1"`, fake.requests[1].Header.Get("If-None-Match"))
+	origin, _ := source.Origin("DATABASE_PORT")
+	assert.Equal(t, server.URL, origin)
+
+	// With the service down, the cache is served.
+	server.Close()
+	source, err = NewHTTPSource(server.URL, WithHTTPCacheFile(cacheFile), WithHTTPRetries(2, time.Millisecond))
+	assert.NoError(t, err)
+	value, _, err = source.LookupErr("DATABASE_HOST")
+	assert.NoError(t, err)
+	assert.Equal(t, "db.internal", value)
+	origin, _ = source.Origin("DATABASE_HOST")
+	assert.Equal(t, cacheFile, origin)
+
+	// Without a cache the failure is reported.
+	source, err = NewHTTPSource(server.URL, WithHTTPCacheFile(filepath.Join(t.TempDir(), "none.json")), WithHTTPRetries(1, 0))
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("DATABASE_HOST")
+	assert.ErrorContains(t, err, "http source "+server.URL+": ")
+}
+
+func TestHTTPSource_CacheFallback(t *testing.T) {
+	fake, server := newFakeConfigService(t)
+	cacheFile := filepath.Join(t.TempDir(), "config-cache.json")
+	source, err := NewHTTPSource(server.URL, WithHTTPCacheFile(cacheFile))
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("DATABASE_HOST")
+	assert.NoError(t, err)
+
+	// A hanging service past the deadline still falls back to the cache.
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hanging.Close()
+	source, err = NewHTTPSource(hanging.URL, WithHTTPCacheFile(cacheFile))
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var cfg struct {
+		Host string `env:"DATABASE_HOST"`
+	}
+	assert.NoError(t, LoadConfigContext(ctx, &cfg, WithSource(source)))
+	assert.Equal(t, "db.internal", cfg.Host)
+
+	// Misconfiguration is reported instead of hidden behind the cache.
+	fake.failures, fake.status = 1, http.StatusUnauthorized
+	source, err = NewHTTPSource(server.URL, WithHTTPCacheFile(cacheFile))
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("DATABASE_HOST")
+	assert.EqualError(t, err, "http source "+server.URL+": 401 Unauthorized")
+
+	// Without a cache file, the last document is reported as coming from
+	// the URL.
+	source, err = NewHTTPSource(server.URL, WithHTTPRetries(1, 0))
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("DATABASE_HOST")
+	assert.NoError(t, err)
+	fake.failures, fake.status = 1, http.StatusServiceUnavailable
+	assert.NoError(t, source.Refresh(context.Background()))
+	origin, _ := source.Origin("DATABASE_HOST")
+	assert.Equal(t, server.URL, origin)
+}
+
+func TestHTTPSource_Errors(t *testing.T) {
+	_, err := NewHTTPSource("")
+	assert.EqualError(t, err, "env_config: http source url is not set")
+
+	fake, server := newFakeConfigService(t)
+	fake.document = `["not", "an", "object"]`
+	source, err := NewHTTPSource(server.URL)
+	assert.NoError(t, err)
+	_, _, err = source.LookupErr("KEY")
+	assert.ErrorContains(t, err, "http source "+server.URL+": json: cannot unmarshal array")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "cache"), 0o700))
+	_, err = NewHTTPSource(server.URL, WithHTTPCacheFile(filepath.Join(dir, "cache")))
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = source.Refresh(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
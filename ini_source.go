@@ -0,0 +1,155 @@
+package env_config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// NewINISource reads an INI file into a MapSource. Sections become key
+// prefixes, with dots in names turned into underscores:
+//
+//	; comment
+//	name = app
+//
+//	[database.replica]
+//	host = "db"        # DATABASE_REPLICA_HOST=db
+//	hosts[] = a        # repeated keys ending in [] build an array:
+//	hosts[] = b        # DATABASE_REPLICA_HOSTS=a,b, ..._HOSTS_0=a, ..._HOSTS_1=b
+//
+// Keys and values are separated by = or :. Lines starting with ; or # are
+// comments, as is the rest of an unquoted value after " ;" or " #". Values
+// may be quoted; double-quoted values support \n, \t, \" and \\ escapes.
+// A later key overrides an earlier one. Malformed input returns a
+// *SyntaxError.
+func NewINISource(path string) (MapSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseINI(path, f)
+}
+
+// ParseINI is like NewINISource but reads the document from r.
+func ParseINI(r io.Reader) (MapSource, error) {
+	return parseINI("", r)
+}
+
+func parseINI(file string, r io.Reader) (MapSource, error) {
+	var (
+		section string
+		line    int
+		keys    []string
+		values  = map[string]interface{}{}
+	)
+	errorf := func(format string, args ...interface{}) error {
+		return &SyntaxError{File: file, Line: line, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" || text[0] == ';' || text[0] == '#' {
+			continue
+		}
+
+		if text[0] == '[' {
+			end := strings.IndexByte(text, ']')
+			if end < 0 {
+				return nil, errorf("unterminated section header %q", text)
+			}
+			if rest := strings.TrimSpace(text[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+				return nil, errorf("unexpected %q after section header", rest)
+			}
+			section = normalizeKey(strings.TrimSpace(text[1:end]))
+			continue
+		}
+
+		sep := strings.IndexAny(text, "=:")
+		if sep < 0 {
+			return nil, errorf("expected = or : after key %s", text)
+		}
+		name := strings.TrimSpace(text[:sep])
+		if name == "" {
+			return nil, errorf("missing key before %c", text[sep])
+		}
+		value, err := parseINIValue(strings.TrimSpace(text[sep+1:]))
+		if err != nil {
+			return nil, errorf("%s", err)
+		}
+
+		isArray := strings.HasSuffix(name, "[]")
+		key := combineKeyPrefix(section, normalizeKey(strings.TrimSuffix(name, "[]")))
+		existing, exists := values[key]
+		if !exists {
+			keys = append(keys, key)
+		}
+		if !isArray {
+			values[key] = value
+			continue
+		}
+		elements, _ := existing.([]interface{})
+		values[key] = append(elements, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	source := MapSource{}
+	for _, key := range keys {
+		flatten(source, key, values[key])
+	}
+	return source, nil
+}
+
+// parseINIValue unquotes value or strips its inline comment.
+func parseINIValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		for i := 1; i < len(value); i++ {
+			if (value[i] == ';' || value[i] == '#') && isSpace(value[i-1]) {
+				return strings.TrimSpace(value[:i]), nil
+			}
+		}
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == quote:
+			if rest := strings.TrimSpace(value[i+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+				return "", fmt.Errorf("unexpected %q after quoted value", rest)
+			}
+			return b.String(), nil
+		case c == '\\' && quote == '"' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(value[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(value[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated quoted value")
+}
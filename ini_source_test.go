@@ -0,0 +1,124 @@
+package env_config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseINI(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    MapSource
+		wantErr string
+	}{
+		{
+			name:  "global keys, sections and comments",
+			input: "\ufeff; comment\n# another\nname = app\nport: 8080\n\n[database]\nhost = db ; inline\nurl = http://x/#anchor\n\n[Database.Replica] ; replica\nmax-conns=10\nempty =\n",
+			want: MapSource{
+				"NAME":                       "app",
+				"PORT":                       "8080",
+				"DATABASE_HOST":              "db",
+				"DATABASE_URL":               "http://x/#anchor",
+				"DATABASE_REPLICA_MAX_CONNS": "10",
+				"DATABASE_REPLICA_EMPTY":     "",
+			},
+		},
+		{
+			name:  "quoting",
+			input: `double = "  padded ; not a comment \"quoted\" \\ \n" ; comment` + "\n" + `single = 'raw \n # kept'` + "\n" + `colon = "a=b:c"`,
+			want: MapSource{
+				"DOUBLE": "  padded ; not a comment \"quoted\" \\ \n",
+				"SINGLE": `raw \n # kept`,
+				"COLON":  "a=b:c",
+			},
+		},
+		{
+			name:  "arrays",
+			input: "[server]\nhosts[] = a\nhosts[] = \"b, c\"\nports[] = 80\n",
+			want: MapSource{
+				"SERVER_HOSTS":   "a,b, c",
+				"SERVER_HOSTS_0": "a",
+				"SERVER_HOSTS_1": "b, c",
+				"SERVER_PORTS":   "80",
+				"SERVER_PORTS_0": "80",
+			},
+		},
+		{
+			name:  "later keys override",
+			input: "a = 1\na = 2\n[s]\nb = 1\n[s]\nb = 3\n",
+			want:  MapSource{"A": "2", "S_B": "3"},
+		},
+		{
+			name:    "missing separator",
+			input:   "a = 1\nflag\n",
+			wantErr: "env_config: line 2: expected = or : after key flag",
+		},
+		{
+			name:    "missing key",
+			input:   "= 1\n",
+			wantErr: "env_config: line 1: missing key before =",
+		},
+		{
+			name:    "unterminated section",
+			input:   "\n[section\n",
+			wantErr: `env_config: line 2: unterminated section header "[section"`,
+		},
+		{
+			name:    "text after section",
+			input:   "[section] extra\n",
+			wantErr: `env_config: line 1: unexpected "extra" after section header`,
+		},
+		{
+			name:    "unterminated quote",
+			input:   "a = \"open\n",
+			wantErr: "env_config: line 1: unterminated quoted value",
+		},
+		{
+			name:    "text after quoted value",
+			input:   "a = 'x' y\n",
+			wantErr: `env_config: line 1: unexpected "y" after quoted value`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseINI(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				var syntaxErr *SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewINISource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.ini")
+	assert.NoError(t, os.WriteFile(path, []byte("[app]\nport = 9090\ntags[] = a\ntags[] = b\n"), 0o600))
+
+	source, err := NewINISource(path)
+	assert.NoError(t, err)
+
+	type Config struct {
+		Port int      `env:"PORT"`
+		Tags []string `env:"TAGS"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithPrefix("APP")))
+	assert.Equal(t, Config{Port: 9090, Tags: []string{"a", "b"}}, cfg)
+
+	_, err = NewINISource(filepath.Join(t.TempDir(), "missing.ini"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.NoError(t, os.WriteFile(path, []byte("[app]\nbroken\n"), 0o600))
+	_, err = NewINISource(path)
+	assert.EqualError(t, err, "env_config: "+path+":2: expected = or : after key broken")
+}
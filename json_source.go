@@ -0,0 +1,45 @@
+package env_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewJSONSource reads a JSON object from path into a MapSource whose keys
+// follow the environment naming used by the tag tree, so
+//
+//	{"database": {"host": "db", "ports": [5432, 5433]}}
+//
+// serves DATABASE_HOST=db, DATABASE_PORTS=5432,5433, DATABASE_PORTS_0=5432
+// and DATABASE_PORTS_1=5433. Numbers keep their original text and null
+// becomes an empty value.
+func NewJSONSource(path string) (MapSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	source, err := ParseJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("env_config: %s: %w", path, err)
+	}
+	return source, nil
+}
+
+// ParseJSON is like NewJSONSource but reads the object from r.
+func ParseJSON(r io.Reader) (MapSource, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	source := MapSource{}
+	flatten(source, "", document)
+	return source, nil
+}
@@ -0,0 +1,106 @@
+package env_config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    MapSource
+		wantErr bool
+	}{
+		{
+			name:  "nested object",
+			input: `{"port": 8080, "ratio": 1.10, "big": 12345678901234567890, "debug": true, "db": {"host": "x", "user": null}}`,
+			want: MapSource{
+				"PORT":    "8080",
+				"RATIO":   "1.10",
+				"BIG":     "12345678901234567890",
+				"DEBUG":   "true",
+				"DB_HOST": "x",
+				"DB_USER": "",
+			},
+		},
+		{
+			name:  "arrays",
+			input: `{"hosts": ["a", "b"], "pools": [{"size": 1}]}`,
+			want:  MapSource{"HOSTS": "a,b", "HOSTS_0": "a", "HOSTS_1": "b", "POOLS_0_SIZE": "1"},
+		},
+		{
+			name:    "top-level array",
+			input:   `[1, 2]`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			input:   `{"port": }`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewJSONSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"app": {
+			"port": 9090,
+			"timeout": "3s",
+			"database": {"host": "db", "ports": [5432, 5433]},
+			"servers": [{"name": "a"}, {"name": "b"}]
+		}
+	}`), 0o600))
+
+	source, err := NewJSONSource(path)
+	assert.NoError(t, err)
+
+	type Server struct {
+		Name string `env:"NAME"`
+	}
+	type Database struct {
+		Host  string `env:"HOST"`
+		Ports []int  `env:"PORTS"`
+	}
+	type Config struct {
+		Port     int           `env:"PORT"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Database Database      `env:"DATABASE"`
+		First    Server        `env:"SERVERS_0"`
+		Second   Server        `env:"SERVERS_1"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithPrefix("APP")))
+	assert.Equal(t, Config{
+		Port:     9090,
+		Timeout:  3 * time.Second,
+		Database: Database{Host: "db", Ports: []int{5432, 5433}},
+		First:    Server{Name: "a"},
+		Second:   Server{Name: "b"},
+	}, cfg)
+
+	_, err = NewJSONSource(filepath.Join(t.TempDir(), "missing.json"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0o600))
+	_, err = NewJSONSource(path)
+	assert.ErrorContains(t, err, path)
+}
@@ -0,0 +1,266 @@
+package env_config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Loader loads configuration structs. Each Loader owns its own strategy,
+// type handler and tag option registries, seeded from the package-level ones
+// when it is created, so libraries sharing a binary can configure parsing
+// independently. A Loader is safe for concurrent use; with WithReport, the
+// report holds the fields of the last Load to finish.
+type Loader struct {
+	source  Source
+	tagName string
+	prefix  string
+	strict  bool
+	report  *Report
+
+	handlers          *TypeHandlerFactory
+	complexStrategies *registry[reflect.Type, TypeStrategy]
+	buildInStrategies *registry[reflect.Kind, TypeStrategy]
+	tagOptionBuilders *registry[string, TagOptionBuilder]
+
+	// err records an invalid option; it is returned by Load and NewStruct.
+	err error
+}
+
+// Option configures a Loader.
+type Option func(*Loader)
+
+// WithSource sets the Source every item in the struct tree reads from.
+// A nil source keeps the default OSSource.
+func WithSource(source Source) Option {
+	return func(l *Loader) {
+		if source != nil {
+			l.source = source
+		}
+	}
+}
+
+// WithTagName sets the struct tag read for each field, DefaultTagName by
+// default.
+func WithTagName(tagName string) Option {
+	return func(l *Loader) {
+		if tagName != "" {
+			l.tagName = tagName
+		}
+	}
+}
+
+// WithPrefix prefixes every key, e.g. WithPrefix("APP") reads PORT from
+// APP_PORT.
+func WithPrefix(prefix string) Option {
+	return func(l *Loader) {
+		l.prefix = prefix
+	}
+}
+
+// WithStrategy registers a TypeStrategy for strategyType on this Loader only.
+func WithStrategy(strategyType reflect.Type, strategy TypeStrategy) Option {
+	return func(l *Loader) {
+		l.complexStrategies.Set(strategyType, strategy)
+	}
+}
+
+// WithTagOption registers a tag option on this Loader only, following the
+// same rules as RegisterTagOption.
+func WithTagOption(name string, builder TagOptionBuilder) Option {
+	return func(l *Loader) {
+		if err := registerTagOption(l.tagOptionBuilders, name, builder); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+}
+
+// WithStrict makes every field behave as if it were tagged required, unless
+// it declares a default= value.
+func WithStrict(strict bool) Option {
+	return func(l *Loader) {
+		l.strict = strict
+	}
+}
+
+// WithReport records the provenance of every loaded field in report. Each
+// Load replaces its content, and so does each Get or Lookup, which leaves
+// the single key it read.
+func WithReport(report *Report) Option {
+	return func(l *Loader) {
+		l.report = report
+	}
+}
+
+// NewLoader creates a Loader reading from the process environment, with the
+// package-level registries as they are at the time of the call.
+func NewLoader(opts ...Option) *Loader {
+	l := &Loader{
+		source:            OSSource{},
+		tagName:           DefaultTagName,
+		handlers:          handlerFactory.clone(),
+		complexStrategies: complexTypeStrategies.Clone(),
+		buildInStrategies: buildInTypeStrategies.Clone(),
+		tagOptionBuilders: tagOptionBuilders.Clone(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load fills cfg, a pointer to a struct, from the Loader's Source.
+func (l *Loader) Load(cfg interface{}) error {
+	return l.LoadContext(context.Background(), cfg)
+}
+
+// LoadContext is like Load but passes ctx to sources, tag options and
+// strategies that accept one. The Source and the tag options implementing
+// Prefetcher are fetched in parallel before any field is loaded. A remote
+// source of this package that fails to fetch is not fetched again during the
+// Load; the returned *MultiError starts with a *FetchError listing the keys
+// it left unresolved. When ctx is done before every key is resolved, it
+// starts with a *TimeoutError listing the keys that were not.
+func (l *Loader) LoadContext(ctx context.Context, cfg interface{}) error {
+	l, publish := l.scopeReport()
+	defer publish()
+
+	root, err := l.NewStruct(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, failures := withFetchFailures(ctx)
+	prefetchAll(ctx, l.prefetchers(root))
+
+	err = root.LoadContext(ctx)
+	if err == nil {
+		return nil
+	}
+	err = fetchErrors(err, failures.errors())
+	if ctx.Err() == nil {
+		return err
+	}
+	return timeoutErrors(ctx, err)
+}
+
+// scopeReport returns a copy of l recording into a report of its own, and a
+// function publishing that report whole to the WithReport one, so concurrent
+// loads sharing it do not mix fields.
+func (l *Loader) scopeReport() (*Loader, func()) {
+	if l.report == nil {
+		return l, func() {}
+	}
+	shared, scoped := l.report, *l
+	scoped.report = &Report{}
+	return &scoped, func() { shared.set(scoped.report.Fields()) }
+}
+
+// prefetchers returns the Source, if it is a Prefetcher, followed by the tag
+// options of every field in root that are.
+func (l *Loader) prefetchers(root StructItem) []Prefetcher {
+	var prefetchers []Prefetcher
+	if prefetcher, ok := l.source.(Prefetcher); ok {
+		prefetchers = append(prefetchers, prefetcher)
+	}
+	var walk func(items []Item)
+	walk = func(items []Item) {
+		for _, item := range items {
+			if parent, ok := item.(interface{ Children() []Item }); ok {
+				walk(parent.Children())
+				continue
+			}
+			for option := item.TagOption(); option != nil; option = option.Next() {
+				if prefetcher, ok := option.(Prefetcher); ok {
+					prefetchers = append(prefetchers, prefetcher)
+				}
+			}
+		}
+	}
+	walk(root.Children())
+	return prefetchers
+}
+
+// fetchErrors gathers the lookups of err that failed on one of failures into
+// a single *FetchError per failure, keeping the other errors as they are.
+func fetchErrors(err error, failures []error) error {
+	if len(failures) == 0 {
+		return err
+	}
+	multi, ok := err.(*MultiError)
+	if !ok {
+		multi = &MultiError{Errors: []error{err}}
+	}
+	fetches := make([]*FetchError, len(failures))
+	for i, failure := range failures {
+		fetches[i] = &FetchError{Err: failure}
+	}
+	var others []error
+	for _, err := range multi.Errors {
+		var sourceErr *SourceError
+		if !errors.As(err, &sourceErr) {
+			others = append(others, err)
+			continue
+		}
+		found := false
+		for _, fetch := range fetches {
+			if errors.Is(sourceErr.Err, fetch.Err) {
+				fetch.Keys = append(fetch.Keys, sourceErr.Key)
+				found = true
+				break
+			}
+		}
+		if !found {
+			others = append(others, err)
+		}
+	}
+
+	var errs []error
+	for _, fetch := range fetches {
+		// A failed source that no field needed does not fail the Load.
+		if len(fetch.Keys) > 0 {
+			errs = append(errs, fetch)
+		}
+	}
+	return &MultiError{Errors: append(errs, others...)}
+}
+
+// timeoutErrors gathers the lookups of err that were cut short by ctx into a
+// single *TimeoutError, keeping the other errors as they are.
+func timeoutErrors(ctx context.Context, err error) error {
+	multi, ok := err.(*MultiError)
+	if !ok {
+		multi = &MultiError{Errors: []error{err}}
+	}
+	timeout := &TimeoutError{Err: ctx.Err()}
+	var others []error
+	for _, err := range multi.Errors {
+		var sourceErr *SourceError
+		if errors.As(err, &sourceErr) && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+			timeout.Keys = append(timeout.Keys, sourceErr.Key)
+			continue
+		}
+		others = append(others, err)
+	}
+	if len(timeout.Keys) == 0 {
+		return err
+	}
+	return &MultiError{Errors: append([]error{timeout}, others...)}
+}
+
+// NewStruct builds the item tree for s without loading it.
+func (l *Loader) NewStruct(s interface{}) (StructItem, error) {
+	if l.err != nil {
+		return StructItem{}, l.err
+	}
+	return l.newStruct(s, l.prefix, "")
+}
+
+// strategy returns the TypeStrategy registered for typ, falling back to the
+// one for its kind.
+func (l *Loader) strategy(typ reflect.Type) (TypeStrategy, bool) {
+	if strategy, ok := l.complexStrategies.Get(typ); ok {
+		return strategy, true
+	}
+	return l.buildInStrategies.Get(typ.Kind())
+}
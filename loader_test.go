@@ -0,0 +1,232 @@
+package env_config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upperStrategy struct{}
+
+func (s upperStrategy) SetValue(field reflect.Value, envValue string, tagOption TagOption) error {
+	field.Set(reflect.ValueOf(customType{Value: strings.ToUpper(envValue)}))
+	return nil
+}
+
+func TestLoader_IndependentRegistries(t *testing.T) {
+	type config struct {
+		Custom customType `env:"CUSTOM"`
+		Name   string     `env:"NAME;upper"`
+	}
+	source := WithSource(MapSource{"CUSTOM": "value", "NAME": "name"})
+
+	upperLoader := NewLoader(source,
+		WithStrategy(reflect.TypeOf(customType{}), upperStrategy{}),
+		WithTagOption("upper", upperOptionBuilder{}),
+	)
+	plainLoader := NewLoader(source,
+		WithStrategy(reflect.TypeOf(customType{}), customTypeStrategy{}),
+		WithTagOption("upper", &LenientOptionBuilder{}),
+	)
+
+	upperCfg, plainCfg := &config{}, &config{}
+	assert.NoError(t, upperLoader.Load(upperCfg))
+	assert.NoError(t, plainLoader.Load(plainCfg))
+
+	assert.Equal(t, &config{Custom: customType{Value: "VALUE"}, Name: "NAME"}, upperCfg)
+	assert.Equal(t, &config{Custom: customType{Value: "value"}, Name: "name"}, plainCfg)
+
+	_, optionRegistered := tagOptionBuilders.Get("upper")
+	assert.False(t, optionRegistered, "WithTagOption must not change the package-level registry")
+	registered, _ := complexTypeStrategies.Get(reflect.TypeOf(customType{}))
+	assert.NotEqual(t, upperStrategy{}, registered)
+}
+
+func TestLoader_Options(t *testing.T) {
+	type database struct {
+		Host string `conf:"HOST"`
+		Port int    `conf:"PORT;default=5432"`
+	}
+	type config struct {
+		Name     string    `conf:"NAME"`
+		Database *database `conf:"DB"`
+		Ignored  string    `env:"NAME"`
+	}
+
+	cfg := &config{}
+	err := NewLoader(
+		WithTagName("conf"),
+		WithPrefix("APP"),
+		WithSource(MapSource{"APP_NAME": "app", "APP_DB_HOST": "db", "NAME": "ignored"}),
+	).Load(cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	assert.Equal(t, &config{Name: "app", Database: &database{Host: "db", Port: 5432}}, cfg)
+}
+
+func TestLoader_Strict(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT;default=8080"`
+		Empty string `env:"EMPTY"`
+		User  string `env:"USER"`
+	}
+
+	err := NewLoader(WithStrict(true), WithSource(MapSource{"EMPTY": ""})).Load(&config{})
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("Load() error = %v, want *MultiError", err)
+	}
+	assert.Equal(t, []error{
+		&MissingError{Path: "config.Host", Key: "HOST"},
+		&MissingError{Path: "config.User", Key: "USER"},
+	}, multi.Errors)
+
+	assert.NoError(t, NewLoader(WithStrict(false), WithSource(MapSource{})).Load(&config{}))
+}
+
+func TestLoader_WithTagOptionDuplicate(t *testing.T) {
+	_, err := NewLoader(WithTagOption(DefaultTagKey, upperOptionBuilder{})).NewStruct(&RedisConfig{})
+	if !errors.Is(err, ErrTagOptionExists) {
+		t.Errorf("NewStruct() error = %v, want %v", err, ErrTagOptionExists)
+	}
+}
+
+func TestNewStruct_WithPrefixOption(t *testing.T) {
+	item, err := NewStruct(&RedisConfig{}, "REDIS", WithPrefix("CACHE"))
+	if err != nil {
+		t.Fatalf("NewStruct() error = %v", err)
+	}
+	assert.Equal(t, "CACHE_HOST", item.Children()[0].Key())
+}
+
+// slowSource answers after delay, like a remote source on a slow network.
+type slowSource struct {
+	remoteValues
+}
+
+func newSlowSource(delay time.Duration, values MapSource) *slowSource {
+	s := &slowSource{}
+	s.fetch = func(ctx context.Context) (MapSource, map[string]string, error) {
+		select {
+		case <-time.After(delay):
+			return values, nil, nil
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	return s
+}
+
+type contextStrategy struct{}
+
+func (contextStrategy) SetValue(reflect.Value, string, TagOption) error {
+	return errors.New("SetValue called instead of SetValueContext")
+}
+
+func (contextStrategy) SetValueContext(ctx context.Context, field reflect.Value, envValue string, _ TagOption) error {
+	field.Set(reflect.ValueOf(customType{Value: envValue + ":" + ctx.Value(contextKey{}).(string)}))
+	return nil
+}
+
+type contextKey struct{}
+
+func TestLoader_LoadContext(t *testing.T) {
+	type config struct {
+		Host   string     `env:"HOST"`
+		Token  string     `env:"TOKEN"`
+		Custom customType `env:"CUSTOM"`
+	}
+
+	const delay = 100 * time.Millisecond
+	source := NewCompositeSource(
+		newSlowSource(delay, MapSource{"HOST": "db"}),
+		newSlowSource(delay, MapSource{"TOKEN": "secret", "CUSTOM": "value"}),
+	)
+	ctx := context.WithValue(context.Background(), contextKey{}, "ctx")
+
+	var cfg config
+	start := time.Now()
+	err := NewLoader(
+		WithSource(source),
+		WithStrategy(reflect.TypeOf(customType{}), contextStrategy{}),
+	).LoadContext(ctx, &cfg)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+
+	assert.Less(t, time.Since(start), 2*delay, "sources should be fetched in parallel")
+	assert.Equal(t, config{Host: "db", Token: "secret", Custom: customType{Value: "value:ctx"}}, cfg)
+}
+
+func TestLoader_LoadContext_FetchFailure(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT"`
+		Token string `env:"TOKEN"`
+		Name  string `env:"NAME"`
+	}
+
+	var fetches atomic.Int32
+	failing := &slowSource{}
+	failing.fetch = func(context.Context) (MapSource, map[string]string, error) {
+		fetches.Add(1)
+		return nil, nil, errors.New("unreachable")
+	}
+	// NAME is found before the failing source is reached.
+	source := NewCompositeSource(failing, MapSource{"NAME": "app", "PORT": "x"})
+
+	err := LoadConfig(&config{}, WithSource(source))
+
+	assert.Equal(t, int32(1), fetches.Load())
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("LoadConfig() error = %v, want *MultiError", err)
+	}
+	if assert.Len(t, multi.Errors, 2) {
+		assert.Equal(t, &FetchError{Keys: []string{"HOST", "TOKEN"}, Err: errors.New("unreachable")}, multi.Errors[0])
+		var parseErr *ParseError
+		assert.ErrorAs(t, multi.Errors[1], &parseErr)
+	}
+}
+
+func TestLoader_LoadContext_Deadline(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT"`
+		Token string `env:"TOKEN"`
+		Key   string `env:"KEY;required"`
+	}
+
+	// Keys found in the MapSource never reach the slow source.
+	source := NewCompositeSource(
+		newSlowSource(time.Minute, MapSource{"TOKEN": "secret", "KEY": "key"}),
+		MapSource{"HOST": "db", "PORT": "x"},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var cfg config
+	err := LoadConfigContext(ctx, &cfg, WithSource(source))
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("LoadConfigContext() error = %v, want *MultiError", err)
+	}
+	if assert.Len(t, multi.Errors, 2) {
+		assert.Equal(t, &TimeoutError{Keys: []string{"TOKEN", "KEY"}, Err: context.DeadlineExceeded}, multi.Errors[0])
+		var parseErr *ParseError
+		assert.ErrorAs(t, multi.Errors[1], &parseErr)
+	}
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "db", cfg.Host)
+}
@@ -0,0 +1,66 @@
+package env_config
+
+import (
+	"maps"
+	"sync"
+)
+
+// registry is a map that is safe for concurrent registration and lookup.
+type registry[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]V
+}
+
+func newRegistry[K comparable, V any](items map[K]V) *registry[K, V] {
+	if items == nil {
+		items = make(map[K]V)
+	}
+	return &registry[K, V]{items: items}
+}
+
+func (r *registry[K, V]) Get(key K) (V, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, ok := r.items[key]
+	return value, ok
+}
+
+func (r *registry[K, V]) Set(key K, value V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[key] = value
+}
+
+// Add sets key only if it is not registered yet and reports whether it did.
+func (r *registry[K, V]) Add(key K, value V) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[key]; ok {
+		return false
+	}
+	r.items[key] = value
+	return true
+}
+
+func (r *registry[K, V]) Delete(key K) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, key)
+}
+
+func (r *registry[K, V]) Keys() []K {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]K, 0, len(r.items))
+	for key := range r.items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Clone returns an independent copy of the registry.
+func (r *registry[K, V]) Clone() *registry[K, V] {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return newRegistry(maps.Clone(r.items))
+}
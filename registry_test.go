@@ -0,0 +1,91 @@
+package env_config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := newRegistry(map[string]int{"a": 1})
+
+	value, ok := r.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	assert.False(t, r.Add("a", 2))
+	assert.True(t, r.Add("b", 2))
+	r.Set("a", 3)
+
+	clone := r.Clone()
+	r.Delete("b")
+
+	keys := clone.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, []string{"a"}, r.Keys())
+
+	value, _ = clone.Get("a")
+	assert.Equal(t, 3, value)
+}
+
+type raceConfig struct {
+	Host    string        `env:"HOST;default=localhost"`
+	Port    int           `env:"PORT;min=1"`
+	Hosts   []string      `env:"HOSTS;delimiter=|"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Custom  customType    `env:"CUSTOM"`
+	Started time.Time     `env:"STARTED"`
+	Nested  *RedisConfig  `env:"REDIS"`
+}
+
+// TestRegistries_Concurrent loads configs from many goroutines while
+// strategies, handlers and tag options are being registered. Run it with
+// -race.
+func TestRegistries_Concurrent(t *testing.T) {
+	const workers = 16
+	source := WithSource(MapSource{
+		"PORT":       "8080",
+		"HOSTS":      "a|b",
+		"TIMEOUT":    "1s",
+		"CUSTOM":     "custom",
+		"STARTED":    "2024-07-17T16:35:34+07:00",
+		"REDIS_HOST": "redis",
+	})
+	shared := NewLoader(source)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+			cfg := &raceConfig{}
+			assert.NoError(t, LoadConfig(cfg, source))
+			assert.Equal(t, 8080, cfg.Port)
+			assert.Equal(t, "redis", cfg.Nested.Host)
+		}()
+
+		go func() {
+			defer wg.Done()
+			cfg := &raceConfig{}
+			assert.NoError(t, shared.Load(cfg))
+			assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		}()
+
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("race_option_%d", i)
+			RegisterStrategy(reflect.TypeOf(customType{}), customTypeStrategy{})
+			RegisterTypeHandler(reflect.TypeOf(time.Time{}), TimeHandler{})
+			assert.NoError(t, RegisterTagOption(name, &LenientOptionBuilder{}))
+			tagOptionBuilders.Delete(name)
+		}(i)
+	}
+	wg.Wait()
+}
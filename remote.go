@@ -0,0 +1,180 @@
+package env_config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// remoteValues holds the values of a remote source, fetched on first use.
+// A failed fetch is not cached, so the next Load tries again; within a Load
+// it is remembered by fetchFailures. Sources embed it to get their Source,
+// FallibleSource, ContextSource, Prefetcher and OriginSource methods.
+type remoteValues struct {
+	fetch func(ctx context.Context) (values MapSource, origins map[string]string, err error)
+
+	mu      sync.Mutex
+	loaded  bool
+	values  MapSource
+	origins map[string]string
+}
+
+// Prefetch fetches the values ahead of the first lookup.
+func (r *remoteValues) Prefetch(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loaded {
+		return nil
+	}
+	failures := fetchFailuresFrom(ctx)
+	if err := failures.get(r); err != nil {
+		return err
+	}
+	values, origins, err := r.fetch(ctx)
+	if err != nil {
+		failures.add(ctx, r, err)
+		return err
+	}
+	r.values, r.origins, r.loaded = values, origins, true
+	return nil
+}
+
+func (r *remoteValues) Lookup(key string) (string, bool) {
+	value, ok, _ := r.LookupErr(key)
+	return value, ok
+}
+
+func (r *remoteValues) LookupErr(key string) (string, bool, error) {
+	return r.LookupContext(context.Background(), key)
+}
+
+func (r *remoteValues) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if err := r.Prefetch(ctx); err != nil {
+		return "", false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.values[key]
+	return value, ok, nil
+}
+
+func (r *remoteValues) Origin(key string) (string, bool) {
+	if err := r.Prefetch(context.Background()); err != nil {
+		return "", false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	origin, ok := r.origins[key]
+	return origin, ok
+}
+
+// set replaces the values, e.g. after a watch noticed a change, and reports
+// whether they differ from the previous ones.
+func (r *remoteValues) set(values MapSource, origins map[string]string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := !r.loaded || !maps.Equal(r.values, values) || !maps.Equal(r.origins, origins)
+	r.values, r.origins, r.loaded = values, origins, true
+	return changed
+}
+
+type fetchFailuresKey struct{}
+
+// fetchFailures remembers the fetches that failed during a Load, so the
+// fields sharing a remote source get the same error instead of fetching it
+// again, and the Load reports it once. Failures caused by the context being
+// done are left to the *TimeoutError.
+type fetchFailures struct {
+	mu     sync.Mutex
+	owners map[interface{}]error
+	errs   []error
+}
+
+// withFetchFailures returns a context recording into a new fetchFailures.
+func withFetchFailures(ctx context.Context) (context.Context, *fetchFailures) {
+	failures := &fetchFailures{owners: map[interface{}]error{}}
+	return context.WithValue(ctx, fetchFailuresKey{}, failures), failures
+}
+
+// fetchFailuresFrom returns the fetchFailures of ctx, or nil outside a Load.
+func fetchFailuresFrom(ctx context.Context) *fetchFailures {
+	failures, _ := ctx.Value(fetchFailuresKey{}).(*fetchFailures)
+	return failures
+}
+
+// get returns the error recorded for owner, the value whose fetch failed.
+func (f *fetchFailures) get(owner interface{}) error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.owners[owner]
+}
+
+func (f *fetchFailures) add(ctx context.Context, owner interface{}, err error) {
+	if f == nil || ctx.Err() != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.owners[owner] = err
+	f.errs = append(f.errs, err)
+}
+
+// errors returns the recorded errors in the order they happened.
+func (f *fetchFailures) errors() []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]error(nil), f.errs...)
+}
+
+// doJSON sends req with client and decodes a 2xx JSON response into out,
+// which may be nil. Other statuses are returned as errors carrying the start
+// of the response body.
+func doJSON(client *http.Client, req *http.Request, out interface{}) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if message := strings.TrimSpace(string(body)); message != "" {
+			return resp, fmt.Errorf("%s: %s", resp.Status, message)
+		}
+		return resp, fmt.Errorf("%s", resp.Status)
+	}
+	if out == nil {
+		return resp, nil
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// prefetchAll runs the prefetchers in parallel and returns their errors.
+func prefetchAll(ctx context.Context, prefetchers []Prefetcher) []error {
+	errs := make([]error, len(prefetchers))
+	var wg sync.WaitGroup
+	for i, prefetcher := range prefetchers {
+		wg.Add(1)
+		go func(i int, prefetcher Prefetcher) {
+			defer wg.Done()
+			errs[i] = prefetcher.Prefetch(ctx)
+		}(i, prefetcher)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,37 @@
+package env_config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteValues(t *testing.T) {
+	calls := 0
+	remote := remoteValues{fetch: func(ctx context.Context) (MapSource, map[string]string, error) {
+		calls++
+		if calls == 1 {
+			return nil, nil, errors.New("unavailable")
+		}
+		return MapSource{"KEY": "value"}, map[string]string{"KEY": "remote"}, nil
+	}}
+
+	// Failures are not cached.
+	_, _, err := remote.LookupErr("KEY")
+	assert.EqualError(t, err, "unavailable")
+
+	value, ok, err := remote.LookupErr("KEY")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+	origin, ok := remote.Origin("KEY")
+	assert.True(t, ok)
+	assert.Equal(t, "remote", origin)
+
+	_, ok, err = remote.LookupErr("MISSING")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,91 @@
+package env_config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// OriginDefault is the origin reported for fields set from their default=
+// tag option.
+const OriginDefault = "default"
+
+// Report lists where each field loaded by a Loader got its value. Pass one to
+// WithReport:
+//
+//	var report env_config.Report
+//	err := env_config.LoadConfig(&cfg, env_config.WithReport(&report))
+//	log.Print(report.String())
+type Report struct {
+	mu     sync.Mutex
+	fields []FieldReport
+}
+
+// FieldReport describes how a single field was loaded.
+type FieldReport struct {
+	// Path is the Go path of the field, such as Config.Database.Host.
+	Path string
+	Key  string
+	// Value is the raw value applied to the field, redacted when the field
+	// is sensitive.
+	Value     string
+	Sensitive bool
+	// Origin is where Value came from: the origin given by the Source (see
+	// OriginSource), OriginDefault, or empty when the field was not set.
+	Origin string
+}
+
+// Fields returns the reported fields in declaration order.
+func (r *Report) Fields() []FieldReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]FieldReport(nil), r.fields...)
+}
+
+// Field returns the report for the field at path.
+func (r *Report) Field(path string) (FieldReport, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, field := range r.fields {
+		if field.Path == path {
+			return field, true
+		}
+	}
+	return FieldReport{}, false
+}
+
+// String formats the report as a table.
+func (r *Report) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tKEY\tVALUE\tORIGIN")
+	for _, field := range r.Fields() {
+		origin := field.Origin
+		if origin == "" {
+			origin = "unset"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%q\t%s\n", field.Path, field.Key, field.Value, origin)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// set replaces the content of the report.
+func (r *Report) set(fields []FieldReport) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fields = fields
+}
+
+func (r *Report) add(field FieldReport) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fields = append(r.fields, field)
+}
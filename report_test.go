@@ -0,0 +1,114 @@
+package env_config
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig_Report(t *testing.T) {
+	type Database struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD;sensitive"`
+	}
+	type Config struct {
+		Port     int      `env:"PORT;default=8080"`
+		Name     string   `env:"NAME"`
+		Token    string   `env:"TOKEN;sensitive"`
+		Level    string   `env:"LEVEL;default=info;emptyIsUnset"`
+		Missing  string   `env:"MISSING"`
+		Database Database `env:"DB"`
+	}
+
+	source := NewCompositeSource(
+		Named("defaults", MapSource{"NAME": "app", "DB_HOST": "localhost"}),
+		Named("secrets", MapSource{"TOKEN": "s3cr3t", "DB_PASSWORD": "hunter2", "LEVEL": ""}),
+	)
+
+	var report Report
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithReport(&report)))
+
+	assert.Equal(t, []FieldReport{
+		{Path: "Config.Port", Key: "PORT", Value: "8080", Origin: OriginDefault},
+		{Path: "Config.Name", Key: "NAME", Value: "app", Origin: "defaults"},
+		{Path: "Config.Token", Key: "TOKEN", Value: redacted, Sensitive: true, Origin: "secrets"},
+		{Path: "Config.Level", Key: "LEVEL", Value: "info", Origin: OriginDefault},
+		{Path: "Config.Missing", Key: "MISSING"},
+		{Path: "Config.Database.Host", Key: "DB_HOST", Value: "localhost", Origin: "defaults"},
+		{Path: "Config.Database.Password", Key: "DB_PASSWORD", Value: redacted, Sensitive: true, Origin: "secrets"},
+	}, report.Fields())
+
+	field, ok := report.Field("Config.Name")
+	assert.True(t, ok)
+	assert.Equal(t, "defaults", field.Origin)
+	_, ok = report.Field("Config.Unknown")
+	assert.False(t, ok)
+
+	assert.Equal(t, `FIELD                     KEY          VALUE        ORIGIN
+Config.Port               PORT         "8080"       default
+Config.Name               NAME         "app"        defaults
+Config.Token              TOKEN        "******"     secrets
+Config.Level              LEVEL        "info"       default
+Config.Missing            MISSING      ""           unset
+Config.Database.Host      DB_HOST      "localhost"  defaults
+Config.Database.Password  DB_PASSWORD  "******"     secrets
+`, report.String())
+
+	// A second Load replaces the previous report.
+	assert.NoError(t, LoadConfig(&Database{}, WithSource(MapSource{}), WithReport(&report)))
+	assert.Len(t, report.Fields(), 2)
+}
+
+func TestLoadConfig_ReportOnError(t *testing.T) {
+	type Config struct {
+		Port int `env:"PORT"`
+	}
+
+	var report Report
+	err := LoadConfig(&Config{}, WithSource(MapSource{"PORT": "abc"}), WithReport(&report))
+	assert.Error(t, err)
+	assert.Equal(t, []FieldReport{
+		{Path: "Config.Port", Key: "PORT", Value: "abc", Origin: "env_config.MapSource"},
+	}, report.Fields())
+}
+
+func TestLoader_ReportConcurrentLoads(t *testing.T) {
+	type Config struct {
+		A string `env:"A"`
+		B string `env:"B"`
+		C string `env:"C"`
+	}
+
+	var report Report
+	loader := NewLoader(WithSource(MapSource{"A": "a", "B": "b", "C": "c"}), WithReport(&report))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, loader.Load(&Config{}))
+		}()
+	}
+	wg.Wait()
+
+	// The report holds a single load, not the fields of all of them.
+	assert.Len(t, report.Fields(), 3)
+}
+
+func TestGet_Report(t *testing.T) {
+	var report Report
+	source := WithSource(MapSource{"PORT": "9090", "HOST": "db"})
+
+	_, err := Get[int]("PORT", "", source, WithReport(&report))
+	assert.NoError(t, err)
+	_, _, err = Lookup[string]("HOST", source, WithReport(&report))
+	assert.NoError(t, err)
+
+	// Each call replaces the report instead of appending to it.
+	assert.Equal(t, []FieldReport{
+		{Path: "HOST", Key: "HOST", Value: "db", Origin: "env_config.MapSource"},
+	}, report.Fields())
+}
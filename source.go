@@ -0,0 +1,119 @@
+package env_config
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// OriginEnvironment is the origin reported for keys read from the process
+// environment.
+const OriginEnvironment = "environment"
+
+var (
+	_ OriginSource = OSSource{}
+	_ Source       = MapSource{}
+	_ OriginSource = namedSource{}
+)
+
+// Source looks up the raw value of a configuration key. The boolean reports
+// whether the key is present, so an empty value can be told apart from a
+// missing one.
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// FallibleSource is implemented by sources whose lookups can fail, such as
+// remote secret stores. Loaders call LookupErr when it is available, so a
+// failure is reported instead of being mistaken for a missing key.
+type FallibleSource interface {
+	Source
+	LookupErr(key string) (string, bool, error)
+}
+
+// ContextSource is implemented by sources that do I/O, so lookups made by
+// LoadConfigContext stop when its context is done.
+type ContextSource interface {
+	Source
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// Prefetcher is implemented by sources and tag options that fetch their
+// values remotely. LoadConfigContext prefetches all of them in parallel
+// before loading fields one by one.
+type Prefetcher interface {
+	Prefetch(ctx context.Context) error
+}
+
+// OriginSource is implemented by sources that can tell where the value of a
+// key comes from, such as a file path. Provenance reports use it.
+type OriginSource interface {
+	Source
+	Origin(key string) (string, bool)
+}
+
+// OSSource reads values from the process environment. It is the default
+// source used by LoadConfig and NewStruct.
+type OSSource struct{}
+
+func (s OSSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (s OSSource) Origin(key string) (string, bool) {
+	if _, ok := os.LookupEnv(key); !ok {
+		return "", false
+	}
+	return OriginEnvironment, true
+}
+
+// MapSource serves values from an in-memory map, which is handy in tests or
+// when values were already collected elsewhere.
+type MapSource map[string]string
+
+func (s MapSource) Lookup(key string) (string, bool) {
+	value, ok := s[key]
+	return value, ok
+}
+
+// Named wraps source so provenance reports show name as the origin of its
+// keys.
+func Named(name string, source Source) OriginSource {
+	return namedSource{name: name, Source: source}
+}
+
+type namedSource struct {
+	Source
+	name string
+}
+
+func (s namedSource) Origin(key string) (string, bool) {
+	if _, ok := s.Lookup(key); !ok {
+		return "", false
+	}
+	return s.name, true
+}
+
+// lookupSource looks key up in source, using LookupContext or LookupErr when
+// source implements them.
+func lookupSource(ctx context.Context, source Source, key string) (string, bool, error) {
+	if contextual, ok := source.(ContextSource); ok {
+		return contextual.LookupContext(ctx, key)
+	}
+	if fallible, ok := source.(FallibleSource); ok {
+		return fallible.LookupErr(key)
+	}
+	value, ok := source.Lookup(key)
+	return value, ok, nil
+}
+
+// sourceOrigin reports where source found key, falling back to the type of
+// source when it does not implement OriginSource.
+func sourceOrigin(source Source, key string) string {
+	if origin, ok := source.(OriginSource); ok {
+		if name, ok := origin.Origin(key); ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", source)
+}
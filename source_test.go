@@ -0,0 +1,88 @@
+package env_config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestOSSource_Lookup(t *testing.T) {
+	os.Setenv("OS_SOURCE_SET", "value")
+	os.Setenv("OS_SOURCE_EMPTY", "")
+	defer os.Unsetenv("OS_SOURCE_SET")
+	defer os.Unsetenv("OS_SOURCE_EMPTY")
+
+	tests := []struct {
+		name        string
+		key         string
+		wantValue   string
+		wantPresent bool
+	}{
+		{
+			name:        "set key",
+			key:         "OS_SOURCE_SET",
+			wantValue:   "value",
+			wantPresent: true,
+		},
+		{
+			name:        "set but empty key",
+			key:         "OS_SOURCE_EMPTY",
+			wantValue:   "",
+			wantPresent: true,
+		},
+		{
+			name:        "unset key",
+			key:         "OS_SOURCE_UNSET",
+			wantValue:   "",
+			wantPresent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, present := OSSource{}.Lookup(tt.key)
+			if value != tt.wantValue || present != tt.wantPresent {
+				t.Errorf("Lookup() = (%q, %v), want (%q, %v)", value, present, tt.wantValue, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestMapSource_Lookup(t *testing.T) {
+	source := MapSource{
+		"SET":   "value",
+		"EMPTY": "",
+	}
+
+	tests := []struct {
+		name        string
+		key         string
+		wantValue   string
+		wantPresent bool
+	}{
+		{
+			name:        "set key",
+			key:         "SET",
+			wantValue:   "value",
+			wantPresent: true,
+		},
+		{
+			name:        "set but empty key",
+			key:         "EMPTY",
+			wantValue:   "",
+			wantPresent: true,
+		},
+		{
+			name:        "unset key",
+			key:         "UNSET",
+			wantValue:   "",
+			wantPresent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, present := source.Lookup(tt.key)
+			if value != tt.wantValue || present != tt.wantPresent {
+				t.Errorf("Lookup() = (%q, %v), want (%q, %v)", value, present, tt.wantValue, tt.wantPresent)
+			}
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package env_config
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 )
@@ -16,8 +17,8 @@ const (
 )
 
 var (
-	_ Item = FieldItem{}
-	_ Item = StructItem{}
+	_ ContextItem = FieldItem{}
+	_ ContextItem = StructItem{}
 )
 
 type Item interface {
@@ -27,11 +28,21 @@ type Item interface {
 	Key() string
 }
 
+// ContextItem is implemented by items that can load under a context. Items
+// returned by custom TypeHandlers that do not implement it are loaded with
+// Load.
+type ContextItem interface {
+	Item
+	LoadContext(ctx context.Context) error
+}
+
 type FieldItem struct {
 	raw       interface{}
 	value     reflect.Value
 	key       string
+	path      string
 	tagOption TagOption
+	loader    *Loader
 }
 
 func (c FieldItem) Key() string {
@@ -47,7 +58,45 @@ func (c FieldItem) Value() reflect.Value {
 }
 
 func (c FieldItem) Load() error {
-	envValue := os.Getenv(c.key)
+	return c.LoadContext(context.Background())
+}
+
+func (c FieldItem) LoadContext(ctx context.Context) error {
+	_, err := c.load(ctx)
+	return err
+}
+
+// load resolves and sets the value, reporting whether the key was present in
+// the Source.
+func (c FieldItem) load(ctx context.Context) (bool, error) {
+	envValue, present, err := c.lookup(ctx)
+	if err != nil {
+		return false, &SourceError{Path: c.path, Key: c.key, Err: err}
+	}
+	if _, ok := findTagOption[*EmptyIsUnsetOption](c.tagOption); ok && envValue == "" {
+		present = false
+	}
+	c.record(envValue, present)
+	if c.loader.strict && !present {
+		_, hasDefault := findTagOption[*DefaultOption](c.tagOption)
+		if !hasDefault {
+			return present, &MissingError{Path: c.path, Key: c.key}
+		}
+	}
+	for option := c.tagOption; option != nil; option = option.Next() {
+		if checker, ok := option.(TagOptionChecker); ok {
+			if err := checker.Check(c.key, envValue, present); err != nil {
+				var missing *MissingError
+				if errors.As(err, &missing) {
+					missing.Path = c.path
+				}
+				return present, err
+			}
+		}
+		if aware, ok := option.(PresenceAware); ok {
+			aware.SetPresent(present)
+		}
+	}
 
 	// Ensure we have the correct kind of value to set
 	value := c.value
@@ -59,35 +108,172 @@ func (c FieldItem) Load() error {
 	}
 
 	if !value.CanSet() {
-		return fmt.Errorf("cannot set value for key %s", c.key)
+		return present, fmt.Errorf("cannot set value for key %s (%s)", c.key, c.path)
 	}
 
-	strategy, exists := complexTypeStrategies[value.Type()]
+	strategy, exists := c.loader.strategy(value.Type())
 	if !exists {
-		strategy, exists = buildInTypeStrategies[value.Kind()]
-		if !exists {
-			return nil
+		return present, &UnsupportedTypeError{Path: c.path, Key: c.key, Type: value.Type()}
+	}
+
+	_, sensitive := findTagOption[*SensitiveOption](c.tagOption)
+	if err := setValue(ctx, strategy, value, envValue, c.TagOption()); err != nil {
+		var rangeErr *RangeError
+		if errors.As(err, &rangeErr) {
+			rangeErr.Key = c.key
+			rangeErr.Sensitive = sensitive
+		}
+		var elementErr *ElementError
+		if errors.As(err, &elementErr) {
+			elementErr.Sensitive = sensitive
+		}
+		effective, _ := c.effectiveValue(envValue, present)
+		return present, &ParseError{
+			Path:      c.path,
+			Key:       c.key,
+			Value:     effective,
+			Type:      value.Type(),
+			Sensitive: sensitive,
+			Err:       err,
+		}
+	}
+
+	// Fields that were neither set nor defaulted keep their zero value and
+	// are not validated; use required to reject them.
+	if _, hasDefault := findTagOption[*DefaultOption](c.tagOption); !present && !hasDefault {
+		return present, nil
+	}
+	for option := c.tagOption; option != nil; option = option.Next() {
+		validator, ok := option.(TagOptionValidator)
+		if !ok {
+			continue
+		}
+		if err := validator.Validate(value); err != nil {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				validationErr.Path = c.path
+				validationErr.Key = c.key
+				validationErr.Sensitive = sensitive
+			}
+			return present, err
+		}
+	}
+	return present, nil
+}
+
+// lookup reads the raw value from the first TagOptionResolver of the field,
+// or from the Loader's Source.
+func (c FieldItem) lookup(ctx context.Context) (string, bool, error) {
+	resolver := c.resolver()
+	if contextual, ok := resolver.(TagOptionContextResolver); ok {
+		return contextual.ResolveContext(ctx, c.key)
+	}
+	if resolver != nil {
+		return resolver.Resolve(c.key)
+	}
+	return lookupSource(ctx, c.loader.source, c.key)
+}
+
+// setValue passes ctx to strategies that accept it.
+func setValue(ctx context.Context, strategy TypeStrategy, field reflect.Value, envValue string, tagOption TagOption) error {
+	if contextual, ok := strategy.(ContextTypeStrategy); ok {
+		return contextual.SetValueContext(ctx, field, envValue, tagOption)
+	}
+	return strategy.SetValue(field, envValue, tagOption)
+}
+
+func (c FieldItem) resolver() TagOptionResolver {
+	for option := c.tagOption; option != nil; option = option.Next() {
+		if resolver, ok := option.(TagOptionResolver); ok {
+			return resolver
 		}
 	}
+	return nil
+}
 
-	return strategy.SetValue(value, envValue, c.TagOption())
+// origin reports where the value of a present key came from. Resolvers are
+// described by their String method when they have one.
+func (c FieldItem) origin() string {
+	resolver := c.resolver()
+	if resolver == nil {
+		return sourceOrigin(c.loader.source, c.key)
+	}
+	if stringer, ok := resolver.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", resolver)
+}
+
+// effectiveValue returns the value the field is set from: the default= value
+// when the key is absent, envValue otherwise.
+func (c FieldItem) effectiveValue(envValue string, present bool) (string, bool) {
+	if option, ok := findTagOption[*DefaultOption](c.tagOption); ok && !present && envValue == "" {
+		return option.DefaultValue, true
+	}
+	return envValue, false
+}
+
+// record adds the field to the Loader's report, if any.
+func (c FieldItem) record(envValue string, present bool) {
+	if c.loader.report == nil {
+		return
+	}
+	field := FieldReport{Path: c.path, Key: c.key}
+	var defaulted bool
+	field.Value, defaulted = c.effectiveValue(envValue, present)
+	if present {
+		field.Origin = c.origin()
+	} else if defaulted {
+		field.Origin = OriginDefault
+	}
+	if _, ok := findTagOption[*SensitiveOption](c.tagOption); ok {
+		field.Sensitive = true
+		if field.Value != "" {
+			field.Value = redacted
+		}
+	}
+	c.loader.report.add(field)
 }
 
 type StructItem struct {
 	raw       interface{}
 	prefix    string
+	path      string
 	value     reflect.Value
 	tagOption TagOption
+	loader    *Loader
 	children  []Item
 }
 
+// Load loads every child and reports all of their errors at once as a
+// *MultiError, so a misconfigured deployment can be fixed in a single pass.
 func (s StructItem) Load() error {
+	return s.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing ctx to the children that accept it.
+func (s StructItem) LoadContext(ctx context.Context) error {
+	var errs []error
 	for _, child := range s.children {
-		if err := child.Load(); err != nil {
-			return err
+		var err error
+		if contextual, ok := child.(ContextItem); ok {
+			err = contextual.LoadContext(ctx)
+		} else {
+			err = child.Load()
+		}
+		if err == nil {
+			continue
+		}
+		if multi, ok := err.(*MultiError); ok {
+			errs = append(errs, multi.Errors...)
+			continue
 		}
+		errs = append(errs, err)
 	}
-	return nil
+	if len(errs) == 0 {
+		return nil
+	}
+	return &MultiError{Errors: errs}
 }
 
 func (s StructItem) Key() string {
@@ -106,25 +292,44 @@ func (s StructItem) Children() []Item {
 	return s.children
 }
 
-func NewStruct(s interface{}, keyPrefix string) (StructItem, error) {
+// NewStruct builds the item tree for s, prefixing every key with keyPrefix,
+// using a Loader configured with opts. A WithPrefix option overrides
+// keyPrefix. Items resolve their values through the Source given by
+// WithSource, or the process environment by default.
+func NewStruct(s interface{}, keyPrefix string, opts ...Option) (StructItem, error) {
+	return NewLoader(append([]Option{WithPrefix(keyPrefix)}, opts...)...).NewStruct(s)
+}
+
+func (l *Loader) newStruct(s interface{}, keyPrefix, path string) (StructItem, error) {
 	val, err := pointerVal(s)
 	if err != nil {
 		return StructItem{}, err
 	}
 
 	typ := val.Type()
+	if path == "" {
+		path = typ.Name()
+	}
 
-	var children []Item
+	var (
+		children []Item
+		errs     []error
+	)
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
 		structField := typ.Field(i)
 
-		envTag := structField.Tag.Get(DefaultTagName)
+		envTag := structField.Tag.Get(l.tagName)
 		if envTag == "" {
 			continue
 		}
 
-		key, nestedTagOpts := parseTagAndKey(envTag)
+		fieldPath := combinePath(path, structField.Name)
+		key, nestedTagOpts, err := l.parseTagAndKey(envTag)
+		if err != nil {
+			errs = append(errs, &TagError{Path: fieldPath, Tag: envTag, Err: err})
+			continue
+		}
 		key = combineKeyPrefix(keyPrefix, key)
 
 		if field.Kind() == reflect.Ptr && field.IsNil() {
@@ -135,18 +340,55 @@ func NewStruct(s interface{}, keyPrefix string) (StructItem, error) {
 		if field.Kind() == reflect.Ptr {
 			fieldType = field.Elem().Type()
 		}
-		handler := handlerFactory.GetHandler(fieldType)
-		children = append(children, handler.Handle(key, field, nestedTagOpts))
+		if err := verifyTagTypes(nestedTagOpts, fieldType); err != nil {
+			errs = append(errs, &TagError{Path: fieldPath, Tag: envTag, Err: err})
+			continue
+		}
+		// Types with their own strategy load as a single field, even when
+		// they are structs.
+		var handler TypeHandler = FieldHandler{}
+		if _, ok := l.complexStrategies.Get(fieldType); !ok {
+			handler = l.handlers.GetHandler(fieldType)
+		}
+		child, err := l.handle(handler, HandlerInput{Key: key, Path: fieldPath, Field: field, TagOption: nestedTagOpts, Loader: l})
+		if err != nil {
+			if multi, ok := err.(*MultiError); ok {
+				errs = append(errs, multi.Errors...)
+			} else {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		children = append(children, child)
+	}
+
+	if len(errs) > 0 {
+		return StructItem{}, &MultiError{Errors: errs}
 	}
 
 	return StructItem{
 		prefix:   keyPrefix,
+		path:     path,
 		raw:      s,
 		value:    val,
+		loader:   l,
 		children: children,
 	}, nil
 }
 
+// handle builds the item for a field, through HandleInput when the handler
+// implements LoaderTypeHandler.
+func (l *Loader) handle(handler TypeHandler, in HandlerInput) (Item, error) {
+	if loaderHandler, ok := handler.(LoaderTypeHandler); ok {
+		return loaderHandler.HandleInput(in)
+	}
+	item := handler.Handle(in.Key, in.Field, in.TagOption)
+	if item == nil {
+		return nil, fmt.Errorf("type handler %T returned no item for %s", handler, in.Path)
+	}
+	return item, nil
+}
+
 func pointerVal(s interface{}) (reflect.Value, error) {
 	val := reflect.ValueOf(s)
 
@@ -160,15 +402,17 @@ func pointerVal(s interface{}) (reflect.Value, error) {
 	return val, nil
 }
 
-func parseTagAndKey(str string) (key string, tag TagOption) {
-	tagStr := strings.Split(str, Semicolon)
-	if len(tagStr) <= 1 {
-		key = str
-		return
+func (l *Loader) parseTagAndKey(str string) (key string, tag TagOption, err error) {
+	segments, err := tokenizeTag(str)
+	if err != nil {
+		return "", nil, err
+	}
+	if segments[0].hasValue {
+		return "", nil, fmt.Errorf("key %q must not contain %s", segments[0].raw, Equal)
 	}
 
-	key = strings.TrimSpace(tagStr[0])
-	tag = parseTag(strings.Join(tagStr[1:], Semicolon))
+	key = strings.TrimSpace(segments[0].name)
+	tag, err = l.buildTagOptions(segments[1:])
 	return
 }
 
@@ -182,3 +426,11 @@ func combineKeyPrefix(prefix, key string) string {
 
 	return prefix + Underscore + key
 }
+
+// combinePath appends a field name to a Go field path such as Config.Database.
+func combinePath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
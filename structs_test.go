@@ -1,6 +1,7 @@
 package env_config
 
 import (
+	"errors"
 	"os"
 	"reflect"
 	"testing"
@@ -14,18 +15,19 @@ func TestParseTagAndKey(t *testing.T) {
 		tag          string
 		expectedKey  string
 		expectedTags TagOption
+		wantErr      bool
 	}{
 		{
-			name:         "Test ParseTagAndKey with single tag",
-			tag:          "CACHE_REDIS_HOST;key=value",
-			expectedKey:  "CACHE_REDIS_HOST",
-			expectedTags: nil,
+			name:        "Test ParseTagAndKey with unknown tag",
+			tag:         "CACHE_REDIS_HOST;key=value",
+			expectedKey: "CACHE_REDIS_HOST",
+			wantErr:     true,
 		},
 		{
-			name:         "Test ParseTagAndKey with multiple tags",
-			tag:          "CACHE_REDIS_HOST;key1=value1;key2=value2",
-			expectedKey:  "CACHE_REDIS_HOST",
-			expectedTags: nil,
+			name:        "Test ParseTagAndKey with multiple unknown tags",
+			tag:         "CACHE_REDIS_HOST;key1=value1;key2=value2",
+			expectedKey: "CACHE_REDIS_HOST",
+			wantErr:     true,
 		},
 		{
 			name:        "Test ParseTagAndKey with multiple tags",
@@ -48,15 +50,31 @@ func TestParseTagAndKey(t *testing.T) {
 			expectedTags: nil,
 		},
 		{
-			name:         "Test tag with spaces",
-			tag:          "CACHE_REDIS_HOST; key1=value1; key2=value2; delimiter= ;  key =3",
-			expectedKey:  "CACHE_REDIS_HOST",
-			expectedTags: nil,
+			name:        "Test tag with spaces and unknown tags",
+			tag:         "CACHE_REDIS_HOST; key1=value1; key2=value2; delimiter= ;  key =3",
+			expectedKey: "CACHE_REDIS_HOST",
+			wantErr:     true,
+		},
+		{
+			name:        "Test tag with spaces",
+			tag:         "CACHE_REDIS_HOST; delimiter= ;  required",
+			expectedKey: "CACHE_REDIS_HOST",
+			expectedTags: &RequiredOption{
+				BaseTagOption: BaseTagOption{
+					next: &DelimiterOption{
+						BaseTagOption: BaseTagOption{},
+						Delimiter:     " ",
+					},
+				},
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			key, tagOption := parseTagAndKey(tt.tag)
+			key, tagOption, err := NewLoader().parseTagAndKey(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTagAndKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
 			if key != tt.expectedKey {
 				t.Errorf("Expected key %s, got %s", tt.expectedKey, key)
 			}
@@ -388,3 +406,89 @@ func compareItems(a, b Item) bool {
 	}
 	return true
 }
+
+type InvalidTagConfig struct {
+	Port     int                  `env:"PORT;defualt=8080"`
+	Database *InvalidTagNested    `env:"DB"`
+	Valid    string               `env:"VALID;default=ok"`
+	Other    *InvalidTagNestedPtr `env:"OTHER"`
+}
+
+type InvalidTagNested struct {
+	Host string `env:"HOST;required=yes"`
+}
+
+type InvalidTagNestedPtr struct {
+	Name string `env:"NAME"`
+}
+
+func TestNewStruct_InvalidTag(t *testing.T) {
+	_, err := NewStruct(&InvalidTagConfig{}, "")
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("NewStruct() error = %v, want *MultiError", err)
+	}
+	if len(multi.Errors) != 2 {
+		t.Fatalf("NewStruct() got %d errors, want 2: %v", len(multi.Errors), err)
+	}
+
+	var tagErr *TagError
+	assert.True(t, errors.As(multi.Errors[0], &tagErr))
+	assert.Equal(t, "InvalidTagConfig.Port", tagErr.Path)
+	assert.Equal(t, "PORT;defualt=8080", tagErr.Tag)
+	assert.Contains(t, tagErr.Error(), `did you mean "default"?`)
+
+	assert.True(t, errors.As(multi.Errors[1], &tagErr))
+	assert.Equal(t, "InvalidTagConfig.Database.Host", tagErr.Path)
+}
+
+type legacyID string
+
+// legacyHandler implements only TypeHandler.Handle.
+type legacyHandler struct{}
+
+func (legacyHandler) Handle(key string, field reflect.Value, tagOption TagOption) Item {
+	return legacyItem{key: key, field: field, tagOption: tagOption}
+}
+
+type legacyItem struct {
+	key       string
+	field     reflect.Value
+	tagOption TagOption
+}
+
+func (i legacyItem) TagOption() TagOption { return i.tagOption }
+func (i legacyItem) Value() reflect.Value { return i.field }
+func (i legacyItem) Key() string          { return i.key }
+func (i legacyItem) Load() error {
+	i.field.SetString("id-" + i.key)
+	return nil
+}
+
+func TestNewStruct_TypeHandlers(t *testing.T) {
+	type config struct {
+		ID   legacyID `env:"ID"`
+		Host string   `env:"HOST"`
+	}
+
+	loader := NewLoader(WithSource(MapSource{"APP_HOST": "db"}), WithPrefix("APP"))
+	loader.handlers.Register(reflect.TypeOf(legacyID("")), legacyHandler{})
+
+	var cfg config
+	assert.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, config{ID: "id-APP_ID", Host: "db"}, cfg)
+
+	var nilHandler struct {
+		Nested struct {
+			Host string `env:"HOST"`
+		} `env:"NESTED"`
+	}
+	loader.handlers.Register(reflect.TypeOf(nilHandler.Nested), nilItemHandler{})
+	_, err := loader.NewStruct(&nilHandler)
+	assert.ErrorContains(t, err, "returned no item")
+}
+
+type nilItemHandler struct{}
+
+func (nilItemHandler) Handle(string, reflect.Value, TagOption) Item { return nil }
@@ -0,0 +1,99 @@
+package env_config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag grammar:
+//
+//	tag     = key { ";" option }
+//	option  = name [ "=" value ]
+//	value   = quoted | { char }
+//	quoted  = "'" { char } "'" | `"` { char } `"`
+//
+// A value that starts with a quote extends to the matching closing quote, so
+// it may contain ";" (default='host=x;port=5432'). Only whitespace may follow
+// the closing quote. A backslash escapes the next character when it is one of
+// \ ; ' ", anywhere in the tag; any other backslash is kept as written so
+// patterns such as pattern=^\d+$ need no doubling.
+
+// tagSegment is one ";"-separated part of a tag.
+type tagSegment struct {
+	name     string
+	value    string
+	hasValue bool
+	// raw is the segment as written, used in error messages.
+	raw string
+}
+
+func isTagEscapable(c byte) bool {
+	return c == '\\' || c == ';' || c == '\'' || c == '"'
+}
+
+func isQuote(c byte) bool {
+	return c == '\'' || c == '"'
+}
+
+// tokenizeTag splits tag into segments, resolving quotes and escapes.
+func tokenizeTag(tag string) ([]tagSegment, error) {
+	var (
+		segments []tagSegment
+		segment  tagSegment
+		buf      strings.Builder
+		start    int
+		// quote is the active quote character, 0 outside quotes.
+		quote byte
+		// closed is set once the closing quote of a value has been read.
+		closed bool
+	)
+
+	flush := func(end int) {
+		if segment.hasValue {
+			segment.value = buf.String()
+		} else {
+			segment.name = buf.String()
+		}
+		segment.raw = tag[start:end]
+		segments = append(segments, segment)
+		segment, closed = tagSegment{}, false
+		buf.Reset()
+		start = end + 1
+	}
+
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote, closed = 0, true
+		case closed && c == ';':
+			flush(i)
+		case closed && c != ' ' && c != '\t':
+			return nil, fmt.Errorf("unexpected %q after quoted value in %q", c, tag[start:])
+		case closed:
+			// whitespace after the closing quote
+		case c == '\\' && i+1 < len(tag) && isTagEscapable(tag[i+1]):
+			i++
+			buf.WriteByte(tag[i])
+		case quote != 0:
+			buf.WriteByte(c)
+		case c == ';':
+			flush(i)
+		case c == '=' && !segment.hasValue:
+			segment.name, segment.hasValue = buf.String(), true
+			buf.Reset()
+			if i+1 < len(tag) && isQuote(tag[i+1]) {
+				i++
+				quote = tag[i]
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, tag[start:])
+	}
+	flush(len(tag))
+
+	return segments, nil
+}
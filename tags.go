@@ -1,6 +1,10 @@
 package env_config
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 )
@@ -13,17 +17,103 @@ type TagOption interface {
 	Next() TagOption
 }
 
+// TagOptionPriority orders the options of a field. parseTag sorts them by
+// ascending priority and links them into a chain, so lower values see the
+// raw value first; options with the same priority keep their tag order.
+// Options that do not implement it get PriorityDefault.
 type TagOptionPriority interface {
 	Priority() int
 }
 
+// Priorities of the built-in options. Options that transform the raw string
+// must sort before PriorityDelimiter, because DelimiterOption splits slice
+// values and ends the Apply chain.
+const (
+	// PriorityFlag is used by flags that FieldItem.Load reads before the
+	// value is converted, such as required and emptyIsUnset.
+	PriorityFlag = -1
+	// PriorityDefault is used by default= and by options without a priority.
+	PriorityDefault = 0
+	// PriorityDelimiter is used by delimiter=.
+	PriorityDelimiter = 1
+	// PriorityValidator is used by validators such as min= and oneof=. They
+	// do not take part in Apply; FieldItem.Load runs them once the value has
+	// been converted.
+	PriorityValidator = 2
+)
+
 type TagOptionBuilder interface {
 	Build() TagOption
 }
 
+// TagOptionChecker is implemented by tag options that validate the raw lookup
+// result before the value is converted, such as required and notEmpty.
+type TagOptionChecker interface {
+	Check(key, value string, present bool) error
+}
+
+// TagOptionValidator is implemented by tag options that validate a field once
+// its value has been converted, such as min= and oneof=.
+type TagOptionValidator interface {
+	Validate(field reflect.Value) error
+}
+
+// TagOptionVerifier is implemented by tag options whose value can be
+// malformed, such as min=abc. parseTag calls Verify once the value is set,
+// so the mistake is reported as a *TagError before anything is loaded.
+type TagOptionVerifier interface {
+	Verify() error
+}
+
+// TagOptionTypeVerifier is implemented by tag options whose value must also
+// suit the type of the field, such as min=1.5 on an int. NewStruct calls
+// VerifyType with the field type, pointers dereferenced, and reports the
+// mistake as a *TagError.
+type TagOptionTypeVerifier interface {
+	VerifyType(typ reflect.Type) error
+}
+
+// TagOptionFlag marks tag options that are written without a value, such as
+// required. parseTag rejects a value for them and requires one for every
+// other option.
+type TagOptionFlag interface {
+	IsFlag()
+}
+
+// TagOptionResolver is implemented by tag options that supply the raw value
+// of a field themselves, such as vault=. FieldItem.Load asks the first
+// resolver in the chain instead of the Source.
+type TagOptionResolver interface {
+	Resolve(key string) (value string, present bool, err error)
+}
+
+// TagOptionContextResolver is implemented by resolvers that do I/O, so
+// LoadConfigContext can stop them when its context is done.
+type TagOptionContextResolver interface {
+	ResolveContext(ctx context.Context, key string) (value string, present bool, err error)
+}
+
+// PresenceAware is implemented by tag options that need to know whether the
+// key was present in the Source before Apply is called.
+type PresenceAware interface {
+	SetPresent(present bool)
+}
+
 const (
 	DefaultTagKey = "default"
 	Delimiter     = "delimiter"
+	EmptyIsUnset  = "emptyIsUnset"
+	Required      = "required"
+	NotEmpty      = "notEmpty"
+	Sensitive     = "sensitive"
+	Lenient       = "lenient"
+	Min           = "min"
+	Max           = "max"
+	Len           = "len"
+	MinLen        = "minlen"
+	MaxLen        = "maxlen"
+	OneOf         = "oneof"
+	Pattern       = "pattern"
 )
 
 const (
@@ -35,12 +125,54 @@ const (
 )
 
 var (
-	tagOptionBuilders = map[string]TagOptionBuilder{
+	// ErrTagOptionExists is returned by RegisterTagOption for a name that is
+	// already registered.
+	ErrTagOptionExists = errors.New("tag option already registered")
+
+	tagOptionBuilders = newRegistry(map[string]TagOptionBuilder{
 		DefaultTagKey: &DefaultOptionBuilder{},
 		Delimiter:     &DelimiterOptionBuilder{},
-	}
+		EmptyIsUnset:  &EmptyIsUnsetOptionBuilder{},
+		Required:      &RequiredOptionBuilder{},
+		NotEmpty:      &NotEmptyOptionBuilder{},
+		Sensitive:     &SensitiveOptionBuilder{},
+		Lenient:       &LenientOptionBuilder{},
+		Min:           &MinOptionBuilder{},
+		Max:           &MaxOptionBuilder{},
+		Len:           &LenOptionBuilder{},
+		MinLen:        &MinLenOptionBuilder{},
+		MaxLen:        &MaxLenOptionBuilder{},
+		OneOf:         &OneOfOptionBuilder{},
+		Pattern:       &PatternOptionBuilder{},
+	})
 )
 
+// RegisterTagOption makes a custom tag option available to every struct
+// loaded afterwards, e.g. RegisterTagOption("vaultpath", builder) enables
+// `env:"DB_PASSWORD;vaultpath=secret/db"`. The builder is called once per
+// tagged field and the option receives the text after "=" via SetValue.
+// See TagOptionPriority for how the option is placed in the chain.
+//
+// Names must be non-empty and must not contain whitespace, ";" or "=".
+// Registering a name twice, including a built-in one, returns
+// ErrTagOptionExists. It is safe to call concurrently with loading.
+func RegisterTagOption(name string, builder TagOptionBuilder) error {
+	return registerTagOption(tagOptionBuilders, name, builder)
+}
+
+func registerTagOption(builders *registry[string, TagOptionBuilder], name string, builder TagOptionBuilder) error {
+	if name == "" || strings.ContainsAny(name, " \t\n"+Semicolon+Equal) {
+		return fmt.Errorf("invalid tag option name %q", name)
+	}
+	if builder == nil {
+		return fmt.Errorf("nil builder for tag option %q", name)
+	}
+	if !builders.Add(name, builder) {
+		return fmt.Errorf("%w: %q", ErrTagOptionExists, name)
+	}
+	return nil
+}
+
 type DefaultOptionBuilder struct{}
 
 func (d *DefaultOptionBuilder) Build() TagOption {
@@ -59,6 +191,46 @@ func (d *DelimiterOptionBuilder) Build() TagOption {
 	}
 }
 
+type EmptyIsUnsetOptionBuilder struct{}
+
+func (e *EmptyIsUnsetOptionBuilder) Build() TagOption {
+	return &EmptyIsUnsetOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type RequiredOptionBuilder struct{}
+
+func (r *RequiredOptionBuilder) Build() TagOption {
+	return &RequiredOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type NotEmptyOptionBuilder struct{}
+
+func (n *NotEmptyOptionBuilder) Build() TagOption {
+	return &NotEmptyOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type SensitiveOptionBuilder struct{}
+
+func (s *SensitiveOptionBuilder) Build() TagOption {
+	return &SensitiveOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type LenientOptionBuilder struct{}
+
+func (l *LenientOptionBuilder) Build() TagOption {
+	return &LenientOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
 // BaseTagOption to hold the next TagOption in the chain
 type BaseTagOption struct {
 	next TagOption
@@ -79,10 +251,13 @@ func (b *BaseTagOption) Apply(value string) (interface{}, error) {
 	return b.next.Apply(value)
 }
 
-// DefaultOption implementation
+// DefaultOption implementation. The default value only replaces keys that
+// are missing from the Source; an explicitly empty value is kept unless the
+// field is also tagged with emptyIsUnset.
 type DefaultOption struct {
 	BaseTagOption
 	DefaultValue string
+	present      bool
 }
 
 func (d *DefaultOption) Next() TagOption {
@@ -97,14 +272,18 @@ func (d *DefaultOption) Apply(value string) (interface{}, error) {
 	if d == nil {
 		return value, nil
 	}
-	if value == "" {
+	if value == "" && !d.present {
 		value = d.DefaultValue
 	}
 	return d.BaseTagOption.Apply(value)
 }
 
+func (d *DefaultOption) SetPresent(present bool) {
+	d.present = present
+}
+
 func (d *DefaultOption) Priority() int {
-	return 0
+	return PriorityDefault
 }
 
 // DelimiterOption implementation
@@ -138,36 +317,174 @@ func (d *DelimiterOption) Apply(value string) (interface{}, error) {
 }
 
 func (d *DelimiterOption) Priority() int {
-	return 1
+	return PriorityDelimiter
+}
+
+// EmptyIsUnsetOption makes an explicitly empty value behave as if the key
+// were not set at all, so default= applies to it as well.
+type EmptyIsUnsetOption struct {
+	BaseTagOption
+}
+
+func (e *EmptyIsUnsetOption) Next() TagOption {
+	return e.next
+}
+
+func (e *EmptyIsUnsetOption) SetValue(string) {}
+
+func (e *EmptyIsUnsetOption) IsFlag() {}
+
+func (e *EmptyIsUnsetOption) Priority() int {
+	return PriorityFlag
+}
+
+// RequiredOption fails the load when the key is missing from the Source.
+// An explicitly empty value satisfies it; use notEmpty to reject that too.
+// A default= on the same field also satisfies it, as it does in strict mode.
+type RequiredOption struct {
+	BaseTagOption
+}
+
+func (r *RequiredOption) Next() TagOption {
+	return r.next
 }
 
-func parseTag(tag string) TagOption {
-	parts := strings.Split(tag, Semicolon)
+func (r *RequiredOption) SetValue(string) {}
+
+func (r *RequiredOption) IsFlag() {}
+
+func (r *RequiredOption) Check(key, _ string, present bool) error {
+	if _, hasDefault := findTagOption[*DefaultOption](r.next); hasDefault {
+		return nil
+	}
+	if !present {
+		return &MissingError{Key: key}
+	}
+	return nil
+}
+
+func (r *RequiredOption) Priority() int {
+	return PriorityFlag
+}
+
+// NotEmptyOption fails the load when the key is missing or set to an empty
+// value.
+type NotEmptyOption struct {
+	BaseTagOption
+}
+
+func (n *NotEmptyOption) Next() TagOption {
+	return n.next
+}
+
+func (n *NotEmptyOption) SetValue(string) {}
+
+func (n *NotEmptyOption) IsFlag() {}
+
+func (n *NotEmptyOption) Check(key, value string, present bool) error {
+	if !present || value == "" {
+		return &MissingError{Key: key, Empty: present}
+	}
+	return nil
+}
+
+func (n *NotEmptyOption) Priority() int {
+	return PriorityFlag
+}
+
+// SensitiveOption marks a field whose value must not appear in errors.
+type SensitiveOption struct {
+	BaseTagOption
+}
+
+func (s *SensitiveOption) Next() TagOption {
+	return s.next
+}
+
+func (s *SensitiveOption) SetValue(string) {}
+
+func (s *SensitiveOption) IsFlag() {}
+
+func (s *SensitiveOption) Priority() int {
+	return PriorityFlag
+}
+
+// LenientOption keeps slice elements that fail to convert as zero values
+// instead of failing the load.
+type LenientOption struct {
+	BaseTagOption
+}
+
+func (l *LenientOption) Next() TagOption {
+	return l.next
+}
+
+func (l *LenientOption) SetValue(string) {}
+
+func (l *LenientOption) IsFlag() {}
+
+func (l *LenientOption) Priority() int {
+	return PriorityFlag
+}
+
+// parseTag builds the option chain of a tag such as "default=8080;required".
+// See tokenizeTag for the quoting rules. Empty segments are ignored. Unknown
+// option names, flags given a value and other options missing one are
+// rejected.
+func (l *Loader) parseTag(tag string) (TagOption, error) {
+	segments, err := tokenizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	return l.buildTagOptions(segments)
+}
+
+func (l *Loader) buildTagOptions(segments []tagSegment) (TagOption, error) {
 	var (
 		head, tail  TagOption
-		tempOptions []TagOptionPriority
+		tempOptions []TagOption
 	)
 
-	for _, tag := range parts {
-		parts := strings.SplitN(tag, "=", 2)
-		if len(parts) == 2 {
-			builder, ok := tagOptionBuilders[parts[0]]
-			if !ok {
-				continue
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.raw) == "" {
+			continue
+		}
+
+		name := strings.TrimSpace(segment.name)
+		if name == "" {
+			return nil, fmt.Errorf("option %q has no name", segment.raw)
+		}
+		builder, ok := l.tagOptionBuilders.Get(name)
+		if !ok {
+			if suggestion := suggestTagOption(l.tagOptionBuilders, name); suggestion != "" {
+				return nil, fmt.Errorf("unknown option %q (did you mean %q?)", name, suggestion)
 			}
-			option := builder.Build()
-			option.SetValue(parts[1])
-			tempOptions = append(tempOptions, option.(TagOptionPriority))
+			return nil, fmt.Errorf("unknown option %q", name)
 		}
 
+		option := builder.Build()
+		_, isFlag := option.(TagOptionFlag)
+		switch {
+		case isFlag && segment.hasValue:
+			return nil, fmt.Errorf("option %q does not take a value", name)
+		case !isFlag && !segment.hasValue:
+			return nil, fmt.Errorf("option %q expects a value, e.g. %s=...", name, name)
+		case segment.hasValue:
+			option.SetValue(segment.value)
+		}
+		if verifier, ok := option.(TagOptionVerifier); ok {
+			if err := verifier.Verify(); err != nil {
+				return nil, fmt.Errorf("option %q: %w", name, err)
+			}
+		}
+		tempOptions = append(tempOptions, option)
 	}
 
-	sort.Slice(tempOptions, func(i, j int) bool {
-		return tempOptions[i].Priority() < tempOptions[j].Priority()
+	sort.SliceStable(tempOptions, func(i, j int) bool {
+		return optionPriority(tempOptions[i]) < optionPriority(tempOptions[j])
 	})
 
-	for _, tempOption := range tempOptions {
-		opt := tempOption.(TagOption)
+	for _, opt := range tempOptions {
 		if head == nil {
 			head = opt
 			tail = opt
@@ -177,7 +494,75 @@ func parseTag(tag string) TagOption {
 		}
 	}
 
-	return head
+	return head, nil
+}
+
+// verifyTagTypes runs every TagOptionTypeVerifier of the chain against typ.
+func verifyTagTypes(option TagOption, typ reflect.Type) error {
+	for ; option != nil; option = option.Next() {
+		if verifier, ok := option.(TagOptionTypeVerifier); ok {
+			if err := verifier.VerifyType(typ); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// suggestTagOption returns the registered option name closest to name, or ""
+// when none is close enough to be a likely typo.
+func suggestTagOption(builders *registry[string, TagOptionBuilder], name string) string {
+	names := builders.Keys()
+	sort.Strings(names)
+
+	best, bestDistance := "", len(name)/2+1
+	for _, registered := range names {
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(registered))
+		if distance < bestDistance {
+			best, bestDistance = registered, distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func optionPriority(option TagOption) int {
+	if p, ok := option.(TagOptionPriority); ok {
+		return p.Priority()
+	}
+	return PriorityDefault
+}
+
+// findTagOption returns the first option of type T in the chain.
+func findTagOption[T TagOption](option TagOption) (T, bool) {
+	for option != nil {
+		if opt, ok := option.(T); ok {
+			return opt, true
+		}
+		option = option.Next()
+	}
+
+	var zero T
+	return zero, false
 }
 
 func defaultTagOption() TagOption {
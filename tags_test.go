@@ -1,6 +1,7 @@
 package env_config
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -73,10 +74,28 @@ func Test_parseTag(t *testing.T) {
 				DefaultValue: "value1,value2,value3",
 			},
 		},
+		{
+			name: "flag option without value",
+			args: args{
+				tag: "default=value;emptyIsUnset",
+			},
+			want: &EmptyIsUnsetOption{
+				BaseTagOption: BaseTagOption{
+					next: &DefaultOption{
+						BaseTagOption: BaseTagOption{},
+						DefaultValue:  "value",
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := parseTag(tt.args.tag); !reflect.DeepEqual(got, tt.want) {
+			got, err := NewLoader().parseTag(tt.args.tag)
+			if err != nil {
+				t.Fatalf("parseTag() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				fmt.Println(tt.args.tag)
 				fmt.Println(strings.Split(tt.args.tag, Semicolon))
 				fmt.Println(len(strings.SplitN(tt.args.tag, Equal, 2)))
@@ -87,3 +106,421 @@ func Test_parseTag(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredOption_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		present bool
+		wantErr bool
+	}{
+		{
+			name:    "present value",
+			value:   "value",
+			present: true,
+			wantErr: false,
+		},
+		{
+			name:    "present empty value",
+			value:   "",
+			present: true,
+			wantErr: false,
+		},
+		{
+			name:    "missing key",
+			value:   "",
+			present: false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := &RequiredOption{}
+			if err := option.Check("KEY", tt.value, tt.present); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequiredOption_CheckWithDefault(t *testing.T) {
+	option := &RequiredOption{BaseTagOption: BaseTagOption{next: &DefaultOption{}}}
+	if err := option.Check("KEY", "", false); err != nil {
+		t.Errorf("Check() error = %v, want nil when a default follows", err)
+	}
+}
+
+func TestNotEmptyOption_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		present bool
+		wantErr bool
+	}{
+		{
+			name:    "present value",
+			value:   "value",
+			present: true,
+			wantErr: false,
+		},
+		{
+			name:    "present empty value",
+			value:   "",
+			present: true,
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			value:   "",
+			present: false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := &NotEmptyOption{}
+			if err := option.Check("KEY", tt.value, tt.present); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type upperOption struct {
+	BaseTagOption
+}
+
+func (u *upperOption) Next() TagOption {
+	return u.next
+}
+
+func (u *upperOption) SetValue(string) {}
+
+func (u *upperOption) IsFlag() {}
+
+func (u *upperOption) Apply(value string) (interface{}, error) {
+	return u.BaseTagOption.Apply(strings.ToUpper(value))
+}
+
+type upperOptionBuilder struct{}
+
+func (u upperOptionBuilder) Build() TagOption {
+	return &upperOption{}
+}
+
+func TestRegisterTagOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  string
+		builder TagOptionBuilder
+		wantErr error
+	}{
+		{
+			name:    "register custom option",
+			option:  "upper",
+			builder: upperOptionBuilder{},
+		},
+		{
+			name:    "duplicate built-in option",
+			option:  DefaultTagKey,
+			builder: upperOptionBuilder{},
+			wantErr: ErrTagOptionExists,
+		},
+		{
+			name:    "invalid name",
+			option:  "up=per",
+			builder: upperOptionBuilder{},
+			wantErr: errors.New("invalid tag option name"),
+		},
+		{
+			name:    "nil builder",
+			option:  "nil_builder",
+			builder: nil,
+			wantErr: errors.New("nil builder"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterTagOption(tt.option, tt.builder)
+			if tt.wantErr == nil {
+				defer tagOptionBuilders.Delete(tt.option)
+				if err != nil {
+					t.Fatalf("RegisterTagOption() error = %v", err)
+				}
+				if builder, _ := tagOptionBuilders.Get(tt.option); !reflect.DeepEqual(builder, tt.builder) {
+					t.Errorf("builder %v not registered", tt.option)
+				}
+				return
+			}
+			if err == nil || (!errors.Is(err, tt.wantErr) && !strings.Contains(err.Error(), tt.wantErr.Error())) {
+				t.Errorf("RegisterTagOption() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("duplicate custom option", func(t *testing.T) {
+		defer tagOptionBuilders.Delete("upper")
+		if err := RegisterTagOption("upper", upperOptionBuilder{}); err != nil {
+			t.Fatalf("RegisterTagOption() error = %v", err)
+		}
+		if err := RegisterTagOption("upper", upperOptionBuilder{}); !errors.Is(err, ErrTagOptionExists) {
+			t.Errorf("RegisterTagOption() error = %v, want %v", err, ErrTagOptionExists)
+		}
+	})
+}
+
+func TestRegisterTagOption_Load(t *testing.T) {
+	if err := RegisterTagOption("upper", upperOptionBuilder{}); err != nil {
+		t.Fatalf("RegisterTagOption() error = %v", err)
+	}
+	defer tagOptionBuilders.Delete("upper")
+
+	cfg := &struct {
+		Level string   `env:"LEVEL;default=info;upper"`
+		Hosts []string `env:"HOSTS;delimiter=|;upper"`
+	}{}
+	err := LoadConfig(cfg, WithSource(MapSource{"HOSTS": "a|b"}))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if cfg.Level != "INFO" || !reflect.DeepEqual(cfg.Hosts, []string{"A", "B"}) {
+		t.Errorf("LoadConfig() = %+v", cfg)
+	}
+}
+
+func Test_parseTag_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		wantErr string
+	}{
+		{
+			name:    "unknown option with suggestion",
+			tag:     "defualt=8080",
+			wantErr: `unknown option "defualt" (did you mean "default"?)`,
+		},
+		{
+			name:    "wrong case with suggestion",
+			tag:     "emptyisunset",
+			wantErr: `unknown option "emptyisunset" (did you mean "emptyIsUnset"?)`,
+		},
+		{
+			name:    "unknown option without suggestion",
+			tag:     "vaultpath=secret/db",
+			wantErr: `unknown option "vaultpath"`,
+		},
+		{
+			name:    "option without value",
+			tag:     "default",
+			wantErr: `option "default" expects a value`,
+		},
+		{
+			name:    "flag with value",
+			tag:     "required=true",
+			wantErr: `option "required" does not take a value`,
+		},
+		{
+			name:    "option without name",
+			tag:     "=8080",
+			wantErr: `option "=8080" has no name`,
+		},
+		{
+			name:    "malformed min",
+			tag:     "min=abc",
+			wantErr: `option "min": "abc" is neither a number nor a duration`,
+		},
+		{
+			name:    "malformed maxlen",
+			tag:     "maxlen=x",
+			wantErr: `option "maxlen": "x" is not a valid length`,
+		},
+		{
+			name:    "invalid pattern",
+			tag:     "pattern=(",
+			wantErr: `option "pattern": error parsing regexp`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLoader().parseTag(tt.tag)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseTag() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_tokenizeTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    []tagSegment
+		wantErr bool
+	}{
+		{
+			name: "plain segments",
+			tag:  "PORT;default=8080;required",
+			want: []tagSegment{
+				{name: "PORT", raw: "PORT"},
+				{name: "default", value: "8080", hasValue: true, raw: "default=8080"},
+				{name: "required", raw: "required"},
+			},
+		},
+		{
+			name: "single quoted value with semicolons",
+			tag:  "DSN;default='host=x;port=5432';required",
+			want: []tagSegment{
+				{name: "DSN", raw: "DSN"},
+				{name: "default", value: "host=x;port=5432", hasValue: true, raw: "default='host=x;port=5432'"},
+				{name: "required", raw: "required"},
+			},
+		},
+		{
+			name: "double quoted delimiter",
+			tag:  `HOSTS;delimiter=";"`,
+			want: []tagSegment{
+				{name: "HOSTS", raw: "HOSTS"},
+				{name: "delimiter", value: ";", hasValue: true, raw: `delimiter=";"`},
+			},
+		},
+		{
+			name: "escaped semicolon",
+			tag:  `HOSTS;delimiter=\;`,
+			want: []tagSegment{
+				{name: "HOSTS", raw: "HOSTS"},
+				{name: "delimiter", value: ";", hasValue: true, raw: `delimiter=\;`},
+			},
+		},
+		{
+			name: "escaped quote inside quotes",
+			tag:  `MSG;default='it\'s'`,
+			want: []tagSegment{
+				{name: "MSG", raw: "MSG"},
+				{name: "default", value: "it's", hasValue: true, raw: `default='it\'s'`},
+			},
+		},
+		{
+			name: "backslash kept before other characters",
+			tag:  `ID;pattern=^\d+$`,
+			want: []tagSegment{
+				{name: "ID", raw: "ID"},
+				{name: "pattern", value: `^\d+$`, hasValue: true, raw: `pattern=^\d+$`},
+			},
+		},
+		{
+			name: "quote inside unquoted value is literal",
+			tag:  "MSG;default=it's",
+			want: []tagSegment{
+				{name: "MSG", raw: "MSG"},
+				{name: "default", value: "it's", hasValue: true, raw: "default=it's"},
+			},
+		},
+		{
+			name: "whitespace after closing quote",
+			tag:  "MSG;default='a' ;required",
+			want: []tagSegment{
+				{name: "MSG", raw: "MSG"},
+				{name: "default", value: "a", hasValue: true, raw: "default='a' "},
+				{name: "required", raw: "required"},
+			},
+		},
+		{
+			name: "equal sign inside value",
+			tag:  "URL;default=a=b",
+			want: []tagSegment{
+				{name: "URL", raw: "URL"},
+				{name: "default", value: "a=b", hasValue: true, raw: "default=a=b"},
+			},
+		},
+		{
+			name:    "unterminated quote",
+			tag:     "DSN;default='host=x;port=5432",
+			wantErr: true,
+		},
+		{
+			name:    "text after closing quote",
+			tag:     "DSN;default='a'b",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenizeTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenizeTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeTag() \ngot  = %#v \nwant = %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// quoteTagValue returns value written so that tokenizeTag reads it back
+// unchanged.
+func quoteTagValue(value string) string {
+	if !strings.ContainsAny(value, `\;'"`) && strings.TrimSpace(value) == value {
+		return value
+	}
+
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' || value[i] == '\'' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(value[i])
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func Test_quoteTagValue_RoundTrip(t *testing.T) {
+	values := []string{
+		"",
+		"8080",
+		" ",
+		";",
+		"host=x;port=5432",
+		"it's",
+		`say "hi"`,
+		`C:\tmp\`,
+		`^\d+;\w*$`,
+		`'quoted'`,
+		`\'`,
+		" padded ",
+		"a|b|c",
+	}
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			tag := "KEY;default=" + quoteTagValue(value) + ";required"
+			key, option, err := NewLoader().parseTagAndKey(tag)
+			if err != nil {
+				t.Fatalf("parseTagAndKey(%q) error = %v", tag, err)
+			}
+
+			defaultOption, ok := findTagOption[*DefaultOption](option)
+			if key != "KEY" || !ok || defaultOption.DefaultValue != value {
+				t.Errorf("parseTagAndKey(%q) = %q, %#v, want default %q", tag, key, option, value)
+			}
+			if _, ok := findTagOption[*RequiredOption](option); !ok {
+				t.Errorf("parseTagAndKey(%q) lost the required option", tag)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_QuotedTagValues(t *testing.T) {
+	cfg := &struct {
+		DSN   string   `env:"DSN;default='host=x;port=5432'"`
+		Hosts []string `env:"HOSTS;delimiter=';'"`
+	}{}
+	if err := LoadConfig(cfg, WithSource(MapSource{"HOSTS": "a;b"})); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if cfg.DSN != "host=x;port=5432" || !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) {
+		t.Errorf("LoadConfig() = %+v", cfg)
+	}
+}
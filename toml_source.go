@@ -0,0 +1,633 @@
+package env_config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewTOMLSource reads a TOML file into a MapSource. Tables become key
+// prefixes like nested JSON objects do, so
+//
+//	[database]
+//	host = "db"
+//	ports = [5432, 5433]
+//
+//	[[servers]]
+//	name = "a"
+//
+// serves DATABASE_HOST=db, DATABASE_PORTS=5432,5433, DATABASE_PORTS_0=5432,
+// DATABASE_PORTS_1=5433 and SERVERS_0_NAME=a. Integers are served in decimal
+// and dates keep their original text. Malformed input returns a *SyntaxError.
+func NewTOMLSource(path string) (MapSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTOML(path, string(data))
+}
+
+// ParseTOML is like NewTOMLSource but reads the document from r.
+func ParseTOML(r io.Reader) (MapSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseTOML("", string(data))
+}
+
+type tomlParser struct {
+	file    string
+	src     string
+	pos     int
+	line    int
+	root    map[string]interface{}
+	current map[string]interface{}
+	// tables records the explicitly defined [tables], which may not be
+	// defined twice.
+	tables map[string]bool
+	// dotted records the tables created by dotted keys, which a [table]
+	// header may not define again.
+	dotted map[uintptr]bool
+	// inline records the inline tables, which are complete once closed.
+	inline map[uintptr]bool
+	// static records the arrays written as values, which [[array]] headers
+	// may not append to.
+	static map[tomlArrayRef]bool
+}
+
+// tomlArrayRef identifies an array by its table and key.
+type tomlArrayRef struct {
+	table uintptr
+	key   string
+}
+
+func parseTOML(file, src string) (MapSource, error) {
+	root := map[string]interface{}{}
+	p := &tomlParser{file: file, src: src, line: 1, root: root, current: root, tables: map[string]bool{}, dotted: map[uintptr]bool{},
+		inline: map[uintptr]bool{}, static: map[tomlArrayRef]bool{}}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			break
+		}
+		var err error
+		if p.peek() == '[' {
+			err = p.parseTable()
+		} else {
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+
+	source := MapSource{}
+	flatten(source, "", root)
+	return source, nil
+}
+
+func (p *tomlParser) parseTable() error {
+	line := p.line
+	isArray := strings.HasPrefix(p.src[p.pos:], "[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	p.skipSpaces()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return p.errorf(line, "expected %s after table name", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := p.descend(p.root, keys[:len(keys)-1], false, line)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	name := strings.Join(keys, ".")
+
+	if isArray {
+		existing, ok := parent[last]
+		tables, isTables := existing.([]interface{})
+		if ok && (!isTables || p.tables[name] || p.static[tomlArrayRef{tableID(parent), last}]) {
+			return p.errorf(line, "key %s is already defined", name)
+		}
+		table := map[string]interface{}{}
+		parent[last] = append(tables, table)
+		p.current = table
+		return nil
+	}
+
+	if p.tables[name] {
+		return p.errorf(line, "table %s is already defined", name)
+	}
+	p.tables[name] = true
+	switch existing := parent[last].(type) {
+	case nil:
+		table := map[string]interface{}{}
+		parent[last] = table
+		p.current = table
+	case map[string]interface{}:
+		if p.dotted[tableID(existing)] {
+			return p.errorf(line, "table %s is already defined by dotted keys", name)
+		}
+		if p.inline[tableID(existing)] {
+			return p.errorf(line, "table %s is already defined inline", name)
+		}
+		p.current = existing
+	default:
+		return p.errorf(line, "key %s is already defined", name)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	line := p.line
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf(line, "expected = after key %s", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpaces()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.descend(table, keys[:len(keys)-1], true, line)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return p.errorf(line, "key %s is already defined", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	if _, ok := value.([]interface{}); ok {
+		p.static[tomlArrayRef{tableID(parent), last}] = true
+	}
+	return nil
+}
+
+// descend walks keys from table, creating missing tables, which are recorded
+// as dotted when dotted is set. Arrays of tables resolve to their last
+// element. Inline tables and static arrays cannot be walked into.
+func (p *tomlParser) descend(table map[string]interface{}, keys []string, dotted bool, line int) (map[string]interface{}, error) {
+	for i, key := range keys {
+		if p.static[tomlArrayRef{tableID(table), key}] {
+			return nil, p.errorf(line, "key %s is not a table", strings.Join(keys[:i+1], "."))
+		}
+		switch next := table[key].(type) {
+		case nil:
+			child := map[string]interface{}{}
+			table[key] = child
+			table = child
+			if dotted {
+				p.dotted[tableID(child)] = true
+			}
+		case map[string]interface{}:
+			if p.inline[tableID(next)] {
+				return nil, p.errorf(line, "table %s is already defined inline", strings.Join(keys[:i+1], "."))
+			}
+			table = next
+		case []interface{}:
+			var last map[string]interface{}
+			if len(next) > 0 {
+				last, _ = next[len(next)-1].(map[string]interface{})
+			}
+			if last == nil {
+				return nil, p.errorf(line, "key %s is not a table", strings.Join(keys[:i+1], "."))
+			}
+			table = last
+		default:
+			return nil, p.errorf(line, "key %s is not a table", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return table, nil
+}
+
+// tableID identifies a table by the address of its map.
+func tableID(table map[string]interface{}) uintptr {
+	return reflect.ValueOf(table).Pointer()
+}
+
+// parseKey reads a bare, quoted or dotted key.
+func (p *tomlParser) parseKey() ([]string, error) {
+	line := p.line
+	var keys []string
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return nil, p.errorf(line, "expected a key")
+		}
+		var (
+			key string
+			err error
+		)
+		switch p.peek() {
+		case '"':
+			key, err = p.parseBasicString()
+		case '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf(line, "invalid key %q", p.restOfLine(start))
+			}
+			key = p.src[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		p.skipSpaces()
+		if p.eof() || p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	line := p.line
+	if p.eof() {
+		return nil, p.errorf(line, "expected a value")
+	}
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return p.parseMultilineString(`"""`, true)
+	case strings.HasPrefix(rest, `'''`):
+		return p.parseMultilineString(`'''`, false)
+	case rest[0] == '"':
+		return p.parseBasicString()
+	case rest[0] == '\'':
+		return p.parseLiteralString()
+	case rest[0] == '[':
+		return p.parseArray()
+	case rest[0] == '{':
+		return p.parseInlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	// A date and a time may be separated by a space.
+	if isTOMLDate(p.src[start:p.pos]) && strings.HasPrefix(p.src[p.pos:], " ") &&
+		len(p.src) >= p.pos+3 && isDigit(p.src[p.pos+1]) && isDigit(p.src[p.pos+2]) {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+			p.pos++
+		}
+	}
+	return p.parseScalar(p.src[start:p.pos], line)
+}
+
+func (p *tomlParser) parseScalar(token string, line int) (string, error) {
+	switch token {
+	case "":
+		return "", p.errorf(line, "expected a value")
+	case "true", "false":
+		return token, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return token, nil
+	}
+	if isTOMLDate(token) || (len(token) > 2 && isDigit(token[0]) && isDigit(token[1]) && token[2] == ':') {
+		return token, nil
+	}
+
+	digits := strings.TrimLeft(token, "+-")
+	if digits == "" || !isDigit(digits[0]) {
+		return "", p.errorf(line, "invalid value %q", token)
+	}
+	if len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		return "", p.errorf(line, "invalid number %s: leading zeros are not allowed", token)
+	}
+	if !strings.ContainsAny(digits, ".eE") || strings.HasPrefix(digits, "0x") {
+		n, err := strconv.ParseInt(token, 0, 64)
+		if err == nil {
+			return strconv.FormatInt(n, 10), nil
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			return "", p.errorf(line, "integer %s is out of range", token)
+		}
+	}
+	float := strings.ReplaceAll(token, "_", "")
+	if _, err := strconv.ParseFloat(float, 64); err == nil && !strings.HasPrefix(digits, "0x") {
+		return float, nil
+	}
+	return "", p.errorf(line, "invalid value %q", token)
+}
+
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	line := p.line
+	p.pos++
+	values := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil, p.errorf(line, "unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return values, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipBlank()
+		if p.eof() {
+			return nil, p.errorf(line, "unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf(p.line, "expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	line := p.line
+	p.pos++
+	table := map[string]interface{}{}
+	p.skipSpaces()
+	if !p.eof() && p.peek() == '}' {
+		p.pos++
+		p.seal(table)
+		return table, nil
+	}
+	for {
+		p.skipSpaces()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.eof() || p.peek() == '\n' {
+			return nil, p.errorf(line, "unterminated inline table")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			p.seal(table)
+			return table, nil
+		default:
+			return nil, p.errorf(line, "expected , or } in inline table")
+		}
+	}
+}
+
+// seal records table and the tables nested in it as inline.
+func (p *tomlParser) seal(table map[string]interface{}) {
+	p.inline[tableID(table)] = true
+	for _, value := range table {
+		if child, ok := value.(map[string]interface{}); ok {
+			p.seal(child)
+		}
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	line := p.line
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf(line, "unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	line := p.line
+	p.pos++
+	end := strings.IndexAny(p.src[p.pos:], "'\n")
+	if end < 0 || p.src[p.pos+end] != '\'' {
+		return "", p.errorf(line, "unterminated string")
+	}
+	value := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value, nil
+}
+
+// parseMultilineString reads a string delimited by three quotes. A newline
+// right after the opening delimiter is dropped and, in basic strings, a
+// backslash at the end of a line trims the following whitespace.
+func (p *tomlParser) parseMultilineString(delim string, escapes bool) (string, error) {
+	line := p.line
+	p.pos += len(delim)
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if strings.HasPrefix(p.src[p.pos:], "\n") {
+		p.pos++
+		p.line++
+	}
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, "unterminated multiline string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], delim) {
+			p.pos += len(delim)
+			// Up to two quotes may directly precede the closing delimiter.
+			for i := 0; i < 2 && !p.eof() && p.peek() == delim[0]; i++ {
+				b.WriteByte(delim[0])
+				p.pos++
+			}
+			return b.String(), nil
+		}
+		c := p.peek()
+		if escapes && c == '\\' {
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t\r")
+			if strings.HasPrefix(rest, "\n") {
+				p.pos++
+				for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+					if p.peek() == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+				continue
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if c == '\n' {
+			p.line++
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf(p.line, "unterminated escape sequence")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"', '\\':
+		b.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return p.errorf(p.line, "invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf(p.line, "invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+size])
+		}
+		b.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return p.errorf(p.line, "invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+// endOfLine accepts trailing whitespace and a comment after a statement.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpaces()
+	if !p.eof() && p.peek() == '#' {
+		p.skipLine()
+	}
+	if p.eof() || p.peek() == '\n' || p.peek() == '\r' {
+		return nil
+	}
+	return p.errorf(p.line, "unexpected %q", p.restOfLine(p.pos))
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case '\n':
+			p.line++
+			p.pos++
+		case ' ', '\t', '\r':
+			p.pos++
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) restOfLine(start int) string {
+	end := strings.IndexByte(p.src[start:], '\n')
+	if end < 0 {
+		return strings.TrimSpace(p.src[start:])
+	}
+	return strings.TrimSpace(p.src[start : start+end])
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) errorf(line int, format string, args ...interface{}) error {
+	return &SyntaxError{File: p.file, Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isTOMLDate reports whether token starts with a YYYY-MM-DD date.
+func isTOMLDate(token string) bool {
+	if len(token) < 10 || token[4] != '-' || token[7] != '-' {
+		return false
+	}
+	for _, i := range []int{0, 1, 2, 3, 5, 6, 8, 9} {
+		if !isDigit(token[i]) {
+			return false
+		}
+	}
+	return true
+}
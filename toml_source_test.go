@@ -0,0 +1,330 @@
+package env_config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    MapSource
+		wantErr string
+	}{
+		{
+			name: "scalars",
+			input: `# comment
+name = "app" # trailing comment
+port = 8080
+big = 1_000_000
+hex = 0xFF
+oct = 0o17
+bin = 0b101
+negative = -17
+ratio = 1_000.5
+exp = 5e+22
+debug = true
+off = false
+inf = inf
+date = 1979-05-27T07:32:00Z
+spaced = 1979-05-27 07:32:00
+local = 07:32:00
+`,
+			want: MapSource{
+				"NAME":     "app",
+				"PORT":     "8080",
+				"BIG":      "1000000",
+				"HEX":      "255",
+				"OCT":      "15",
+				"BIN":      "5",
+				"NEGATIVE": "-17",
+				"RATIO":    "1000.5",
+				"EXP":      "5e+22",
+				"DEBUG":    "true",
+				"OFF":      "false",
+				"INF":      "inf",
+				"DATE":     "1979-05-27T07:32:00Z",
+				"SPACED":   "1979-05-27 07:32:00",
+				"LOCAL":    "07:32:00",
+			},
+		},
+		{
+			name: "strings",
+			input: `basic = "tab\tquote\" back\\ \u00e9 \U0001F600"
+literal = 'C:\Users\#not-a-comment'
+multi = """
+first
+second"""
+trimmed = """\
+    one \
+    two"""
+quotes = """a ""quoted"" word"""""
+raw = '''
+no \escapes'''
+`,
+			want: MapSource{
+				"BASIC":   "tab\tquote\" back\\ é 😀",
+				"LITERAL": `C:\Users\#not-a-comment`,
+				"MULTI":   "first\nsecond",
+				"TRIMMED": "one two",
+				"QUOTES":  `a ""quoted"" word""`,
+				"RAW":     `no \escapes`,
+			},
+		},
+		{
+			name: "keys",
+			input: `bare-key = 1
+"quoted key" = 2
+'literal.key' = 3
+database.host = "db"
+database . port = 5432
+`,
+			want: MapSource{
+				"BARE_KEY":      "1",
+				"QUOTED_KEY":    "2",
+				"LITERAL_KEY":   "3",
+				"DATABASE_HOST": "db",
+				"DATABASE_PORT": "5432",
+			},
+		},
+		{
+			name: "arrays",
+			input: `ports = [80, 443]
+empty = []
+multiline = [
+  "a", # first
+  "b",
+]
+nested = [[1, 2], ["x"]]
+`,
+			want: MapSource{
+				"PORTS":       "80,443",
+				"PORTS_0":     "80",
+				"PORTS_1":     "443",
+				"EMPTY":       "",
+				"MULTILINE":   "a,b",
+				"MULTILINE_0": "a",
+				"MULTILINE_1": "b",
+				"NESTED_0":    "1,2",
+				"NESTED_0_0":  "1",
+				"NESTED_0_1":  "2",
+				"NESTED_1":    "x",
+				"NESTED_1_0":  "x",
+			},
+		},
+		{
+			name: "tables",
+			input: `title = "root"
+
+[database]
+host = "db"
+
+[database.replica]
+host = "replica"
+
+[a.b.c]
+d = 1
+
+[a]
+e = 2
+`,
+			want: MapSource{
+				"TITLE":                 "root",
+				"DATABASE_HOST":         "db",
+				"DATABASE_REPLICA_HOST": "replica",
+				"A_B_C_D":               "1",
+				"A_E":                   "2",
+			},
+		},
+		{
+			name: "inline tables and arrays of tables",
+			input: `point = { x = 1, y.z = 2 }
+empty = {}
+
+[[servers]]
+name = "a"
+[servers.tls]
+enabled = true
+
+[[servers]]
+name = "b"
+pools = [{ size = 1 }]
+`,
+			want: MapSource{
+				"POINT_X":                "1",
+				"POINT_Y_Z":              "2",
+				"SERVERS_0_NAME":         "a",
+				"SERVERS_0_TLS_ENABLED":  "true",
+				"SERVERS_1_NAME":         "b",
+				"SERVERS_1_POOLS_0_SIZE": "1",
+			},
+		},
+		{
+			name:  "sub-table of a dotted key table",
+			input: "[fruit]\napple.color = \"red\"\n[fruit.apple.texture]\nsmooth = true\n",
+			want: MapSource{
+				"FRUIT_APPLE_COLOR":          "red",
+				"FRUIT_APPLE_TEXTURE_SMOOTH": "true",
+			},
+		},
+		{
+			name:    "table defined by dotted keys",
+			input:   "[a]\nx.y = 1\n[a.x]\n",
+			wantErr: "env_config: line 3: table a.x is already defined by dotted keys",
+		},
+		{
+			name:    "inline table extended by a header",
+			input:   "a = { x = 1 }\n[a]\ny = 2\n",
+			wantErr: "env_config: line 2: table a is already defined inline",
+		},
+		{
+			name:    "inline table extended by a sub-table header",
+			input:   "a = { x.y = 1 }\n[a.x.z]\n",
+			wantErr: "env_config: line 2: table a is already defined inline",
+		},
+		{
+			name:    "inline table extended by dotted keys",
+			input:   "a = { x = 1 }\na.y = 2\n",
+			wantErr: "env_config: line 2: table a is already defined inline",
+		},
+		{
+			name:    "array of tables over a static array",
+			input:   "a = []\n[[a]]\n",
+			wantErr: "env_config: line 2: key a is already defined",
+		},
+		{
+			name:    "table inside a static array",
+			input:   "a = [{ x = 1 }]\n[a.y]\n",
+			wantErr: "env_config: line 2: key a is not a table",
+		},
+		{
+			name:    "integer out of range",
+			input:   "g = 99999999999999999999\n",
+			wantErr: "env_config: line 1: integer 99999999999999999999 is out of range",
+		},
+		{
+			name:    "duplicate key",
+			input:   "a = 1\nb = 2\na = 3\n",
+			wantErr: "env_config: line 3: key a is already defined",
+		},
+		{
+			name:    "duplicate table",
+			input:   "[a]\nx = 1\n[a]\n",
+			wantErr: "env_config: line 3: table a is already defined",
+		},
+		{
+			name:    "table over a value",
+			input:   "a = 1\n[a.b]\n",
+			wantErr: "env_config: line 2: key a is not a table",
+		},
+		{
+			name:    "missing equals",
+			input:   "\n\nkey \"value\"\n",
+			wantErr: "env_config: line 3: expected = after key key",
+		},
+		{
+			name:    "missing value",
+			input:   "key =\n",
+			wantErr: "env_config: line 1: expected a value",
+		},
+		{
+			name:    "invalid value",
+			input:   "key = value\n",
+			wantErr: `env_config: line 1: invalid value "value"`,
+		},
+		{
+			name:    "leading zeros",
+			input:   "key = 0755\n",
+			wantErr: "env_config: line 1: invalid number 0755: leading zeros are not allowed",
+		},
+		{
+			name:    "unterminated string",
+			input:   "key = \"open\nnext = 1\n",
+			wantErr: "env_config: line 1: unterminated string",
+		},
+		{
+			name:    "unterminated multiline string",
+			input:   "a = 1\nkey = \"\"\"open\n\n",
+			wantErr: "env_config: line 2: unterminated multiline string",
+		},
+		{
+			name:    "invalid escape",
+			input:   `key = "\x"`,
+			wantErr: `env_config: line 1: invalid escape sequence \x`,
+		},
+		{
+			name:    "unterminated array",
+			input:   "key = [1,\n2\n",
+			wantErr: "env_config: line 1: unterminated array",
+		},
+		{
+			name:    "unterminated table header",
+			input:   "[table\n",
+			wantErr: "env_config: line 1: expected ] after table name",
+		},
+		{
+			name:    "unterminated inline table",
+			input:   "key = { a = 1\n}",
+			wantErr: "env_config: line 1: unterminated inline table",
+		},
+		{
+			name:    "trailing garbage",
+			input:   "key = 1 2\n",
+			wantErr: `env_config: line 1: unexpected "2"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTOML(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				var syntaxErr *SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewTOMLSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+[app]
+timeout = "5s"
+hosts = ["a", "b"]
+
+[app.database]
+port = 5432
+`), 0o600))
+
+	source, err := NewTOMLSource(path)
+	assert.NoError(t, err)
+
+	type Database struct {
+		Port int `env:"PORT"`
+	}
+	type Config struct {
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Hosts    []string      `env:"HOSTS"`
+		Database Database      `env:"DATABASE"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source), WithPrefix("APP")))
+	assert.Equal(t, Config{Timeout: 5 * time.Second, Hosts: []string{"a", "b"}, Database: Database{Port: 5432}}, cfg)
+
+	_, err = NewTOMLSource(filepath.Join(t.TempDir(), "missing.toml"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.NoError(t, os.WriteFile(path, []byte("ok = 1\nbroken\n"), 0o600))
+	_, err = NewTOMLSource(path)
+	assert.EqualError(t, err, "env_config: "+path+":2: expected = after key broken")
+}
@@ -9,24 +9,67 @@ type TypeHandler interface {
 	Handle(key string, field reflect.Value, nestedTagOpts TagOption) Item
 }
 
+// HandlerInput describes the field a LoaderTypeHandler builds an item for.
+type HandlerInput struct {
+	Key string
+	// Path is the Go field path, e.g. Config.Database.Port.
+	Path      string
+	Field     reflect.Value
+	TagOption TagOption
+	// Loader is the Loader building the struct. Items it creates should read
+	// from its Source and registries.
+	Loader *Loader
+}
+
+// LoaderTypeHandler is implemented by handlers that need the field path or
+// the Loader, or that can fail. The Loader calls HandleInput instead of
+// Handle when a handler implements it.
+type LoaderTypeHandler interface {
+	TypeHandler
+	HandleInput(in HandlerInput) (Item, error)
+}
+
+var (
+	_ LoaderTypeHandler = TimeHandler{}
+	_ LoaderTypeHandler = StructHandler{}
+	_ LoaderTypeHandler = FieldHandler{}
+)
+
 type TypeHandlerFactory struct {
-	handlers map[reflect.Type]TypeHandler
+	handlers *registry[reflect.Type, TypeHandler]
 }
 
 var (
 	handlerFactory = NewTypeHandlerFactory()
 )
 
+// RegisterTypeHandler registers a TypeHandler for t with every Loader created
+// afterwards. It is safe to call concurrently with loading.
+func RegisterTypeHandler(t reflect.Type, handler TypeHandler) {
+	handlerFactory.Register(t, handler)
+}
+
 func NewTypeHandlerFactory() *TypeHandlerFactory {
 	return &TypeHandlerFactory{
-		handlers: map[reflect.Type]TypeHandler{
+		handlers: newRegistry(map[reflect.Type]TypeHandler{
 			reflect.TypeOf(time.Time{}): TimeHandler{},
-		},
+		}),
+	}
+}
+
+// Register sets the handler used for fields of type t.
+func (f *TypeHandlerFactory) Register(t reflect.Type, handler TypeHandler) {
+	f.handlers.Set(t, handler)
+}
+
+func (f *TypeHandlerFactory) clone() *TypeHandlerFactory {
+	return &TypeHandlerFactory{
+		handlers: f.handlers.Clone(),
 	}
 }
 
 func (f *TypeHandlerFactory) GetHandler(t reflect.Type) TypeHandler {
-	if handler, ok := f.handlers[t]; ok {
+	if handler, ok := f.handlers.Get(t); ok {
 		return handler
 	}
 
@@ -41,34 +84,49 @@ func (f *TypeHandlerFactory) GetHandler(t reflect.Type) TypeHandler {
 type TimeHandler struct{}
 
 func (h TimeHandler) Handle(key string, field reflect.Value, nestedTagOpt TagOption) Item {
-	return FieldItem{
-		raw:       field.Interface(),
-		key:       key,
-		value:     field,
-		tagOption: nestedTagOpt,
-	}
+	return FieldHandler{}.Handle(key, field, nestedTagOpt)
+}
+
+func (h TimeHandler) HandleInput(in HandlerInput) (Item, error) {
+	return FieldHandler{}.HandleInput(in)
 }
 
 type StructHandler struct{}
 
+// Handle builds the nested struct with a default Loader. It returns nil when
+// the struct cannot be built; HandleInput reports the error.
 func (h StructHandler) Handle(key string, field reflect.Value, _ TagOption) Item {
-	if field.Kind() == reflect.Ptr {
-		field = field.Elem()
-	}
-	childStruct, err := NewStruct(field.Addr().Interface(), key)
+	item, err := h.HandleInput(HandlerInput{Key: key, Field: field, Loader: NewLoader()})
 	if err != nil {
 		return nil
 	}
-	return childStruct
+	return item
+}
+
+func (h StructHandler) HandleInput(in HandlerInput) (Item, error) {
+	field := in.Field
+	if field.Kind() == reflect.Ptr {
+		field = field.Elem()
+	}
+	return in.Loader.newStruct(field.Addr().Interface(), in.Key, in.Path)
 }
 
 type FieldHandler struct{}
 
+// Handle builds a field read by a default Loader, from the process
+// environment.
 func (h FieldHandler) Handle(key string, field reflect.Value, nestedTagOpt TagOption) Item {
+	item, _ := h.HandleInput(HandlerInput{Key: key, Path: key, Field: field, TagOption: nestedTagOpt, Loader: NewLoader()})
+	return item
+}
+
+func (h FieldHandler) HandleInput(in HandlerInput) (Item, error) {
 	return FieldItem{
-		raw:       field.Interface(),
-		key:       key,
-		value:     field,
-		tagOption: nestedTagOpt,
-	}
+		raw:       in.Field.Interface(),
+		key:       in.Key,
+		path:      in.Path,
+		value:     in.Field,
+		tagOption: in.TagOption,
+		loader:    in.Loader,
+	}, nil
 }
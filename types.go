@@ -1,6 +1,7 @@
 package env_config
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -12,13 +13,22 @@ type TypeStrategy interface {
 	SetValue(field reflect.Value, envValue string, tagOption TagOption) error
 }
 
+// ContextTypeStrategy is implemented by strategies that do I/O while setting
+// a value. LoadConfigContext calls SetValueContext instead of SetValue.
+type ContextTypeStrategy interface {
+	SetValueContext(ctx context.Context, field reflect.Value, envValue string, tagOption TagOption) error
+}
+
+// RegisterStrategy registers a TypeStrategy for strategyType with every Loader
+// created afterwards, including the one used by LoadConfig. It is safe to
+// call concurrently with loading.
 func RegisterStrategy(strategyType reflect.Type, strategy TypeStrategy) {
-	complexTypeStrategies[strategyType] = strategy
+	complexTypeStrategies.Set(strategyType, strategy)
 }
 
 var (
-	complexTypeStrategies = make(map[reflect.Type]TypeStrategy)
-	buildInTypeStrategies = make(map[reflect.Kind]TypeStrategy)
+	complexTypeStrategies *registry[reflect.Type, TypeStrategy]
+	buildInTypeStrategies *registry[reflect.Kind, TypeStrategy]
 )
 
 type StringStrategy struct{}
@@ -53,12 +63,12 @@ func (s IntStrategy[I]) SetValue(field reflect.Value, envValue string, tagOption
 		return nil
 	}
 
-	v, err := strconv.ParseInt(value, 10, 64)
+	v, err := parseInt[I](value)
 	if err != nil {
 		return err
 	}
 
-	field.SetInt(v)
+	field.SetInt(int64(v))
 	return nil
 }
 
@@ -78,11 +88,11 @@ func (s UintStrategy[U]) SetValue(field reflect.Value, envValue string, tagOptio
 		return nil
 	}
 
-	v, err := strconv.ParseUint(value, 10, 64)
+	v, err := parseUint[U](value)
 	if err != nil {
 		return err
 	}
-	field.SetUint(v)
+	field.SetUint(uint64(v))
 	return nil
 }
 
@@ -102,11 +112,11 @@ func (s FloatStrategy[F]) SetValue(field reflect.Value, envValue string, tagOpti
 		return nil
 	}
 
-	v, err := strconv.ParseFloat(value, 64)
+	v, err := parseFloat[F](value)
 	if err != nil {
 		return err
 	}
-	field.SetFloat(v)
+	field.SetFloat(float64(v))
 	return nil
 }
 
@@ -133,6 +143,13 @@ func (s BoolStrategy) SetValue(field reflect.Value, envValue string, tagOption T
 	return nil
 }
 
+// RawBytes is a []byte loaded with the raw bytes of the value, e.g. a key or
+// a certificate. A plain []byte ([]uint8) is a list of numbers like the other
+// integer slices.
+type RawBytes []byte
+
+// ByteSliceStrategy sets a byte slice to the raw bytes of the value. It is
+// used for RawBytes and other named byte slice types.
 type ByteSliceStrategy struct{}
 
 func (s ByteSliceStrategy) SetValue(field reflect.Value, envValue string, tagOption TagOption) error {
@@ -198,7 +215,6 @@ func (s StringSliceStrategy) SetValue(v reflect.Value, envValue string, tagOptio
 		return fmt.Errorf("invalid type, expected []string but got %s", v.Kind())
 	}
 
-	tagOption = setStringSliceDefaultTagOption(tagOption)
 	values, err := parseOptionValues(envValue, tagOption)
 	if err != nil {
 		return err
@@ -208,25 +224,22 @@ func (s StringSliceStrategy) SetValue(v reflect.Value, envValue string, tagOptio
 	return nil
 }
 
-func setStringSliceDefaultTagOption(tagOption TagOption) TagOption {
+// withDelimiterOption makes sure a slice strategy always splits its value,
+// appending the default DelimiterOption to chains that do not declare one.
+func withDelimiterOption(tagOption TagOption) TagOption {
 	if tagOption == nil {
-		return tagOption
+		return defaultTagOption()
 	}
 
-	tag := tagOption
-	hasDelimiter := false
-	for tag != nil {
-		if _, ok := tag.(*DelimiterOption); ok {
-			hasDelimiter = true
-			break
-		}
-		tag = tag.Next()
+	if _, ok := findTagOption[*DelimiterOption](tagOption); ok {
+		return tagOption
 	}
 
-	if !hasDelimiter {
-		tagOption.SetNext(defaultTagOption())
+	tail := tagOption
+	for tail.Next() != nil {
+		tail = tail.Next()
 	}
-
+	tail.SetNext(defaultTagOption())
 	return tagOption
 }
 
@@ -242,13 +255,9 @@ func (s BoolSliceStrategy) SetValue(v reflect.Value, envValue string, tagOption
 		return err
 	}
 
-	boolValues := make([]bool, len(values))
-	for i, val := range values {
-		var b bool
-		b, err := strconv.ParseBool(val)
-		if err == nil {
-			boolValues[i] = b
-		}
+	boolValues, err := StringArrayToBoolArray(values)
+	if err != nil && !isLenient(tagOption) {
+		return err
 	}
 	v.Set(reflect.ValueOf(boolValues))
 	return nil
@@ -266,7 +275,10 @@ func (s IntSliceStrategy[I]) SetValue(v reflect.Value, envValue string, tagOptio
 		return err
 	}
 
-	intValues := StringArrayToIntArray[I](values)
+	intValues, err := StringArrayToIntArray[I](values)
+	if err != nil && !isLenient(tagOption) {
+		return err
+	}
 	v.Set(reflect.ValueOf(intValues))
 	return nil
 }
@@ -283,7 +295,10 @@ func (s UintSliceStrategy[U]) SetValue(v reflect.Value, envValue string, tagOpti
 		return err
 	}
 
-	uintValues := StringArrayToUintArray[U](values)
+	uintValues, err := StringArrayToUintArray[U](values)
+	if err != nil && !isLenient(tagOption) {
+		return err
+	}
 	v.Set(reflect.ValueOf(uintValues))
 	return nil
 }
@@ -300,11 +315,21 @@ func (s FloatSliceStrategy[F]) SetValue(v reflect.Value, envValue string, tagOpt
 		return err
 	}
 
-	floatValues := StringArrayToFloatArray[F](values)
+	floatValues, err := StringArrayToFloatArray[F](values)
+	if err != nil && !isLenient(tagOption) {
+		return err
+	}
 	v.Set(reflect.ValueOf(floatValues))
 	return nil
 }
 
+// isLenient reports whether the field is tagged with lenient, in which case
+// slice elements that fail to convert are left as zero values.
+func isLenient(tagOption TagOption) bool {
+	_, ok := findTagOption[*LenientOption](tagOption)
+	return ok
+}
+
 func parseOptionValue(envValue string, option TagOption) (string, error) {
 	if option == nil {
 		return envValue, nil
@@ -319,9 +344,7 @@ func parseOptionValue(envValue string, option TagOption) (string, error) {
 }
 
 func parseOptionValues(envValue string, option TagOption) ([]string, error) {
-	if option == nil {
-		return parseOptionValues(envValue, defaultTagOption())
-	}
+	option = withDelimiterOption(option)
 
 	value, err := option.Apply(envValue)
 	if err != nil {
@@ -332,7 +355,7 @@ func parseOptionValues(envValue string, option TagOption) ([]string, error) {
 	return valueArr, nil
 }
 func init() {
-	buildInTypeStrategies = map[reflect.Kind]TypeStrategy{
+	buildInTypeStrategies = newRegistry(map[reflect.Kind]TypeStrategy{
 		reflect.String:  StringStrategy{},
 		reflect.Int:     IntStrategy[int]{},
 		reflect.Int8:    IntStrategy[int8]{},
@@ -348,9 +371,9 @@ func init() {
 		reflect.Float64: FloatStrategy[float64]{},
 		reflect.Bool:    BoolStrategy{},
 		reflect.Slice:   ByteSliceStrategy{},
-	}
+	})
 
-	complexTypeStrategies = map[reflect.Type]TypeStrategy{
+	complexTypeStrategies = newRegistry(map[reflect.Type]TypeStrategy{
 		reflect.TypeOf(time.Duration(0)): DurationStrategy{},
 		reflect.TypeOf(time.Time{}):      TimeStrategy{},
 		reflect.TypeOf([]string{}):       StringSliceStrategy{},
@@ -367,5 +390,6 @@ func init() {
 		reflect.TypeOf([]uint64{}):       UintSliceStrategy[uint64]{},
 		reflect.TypeOf([]float64{}):      FloatSliceStrategy[float64]{},
 		reflect.TypeOf([]float32{}):      FloatSliceStrategy[float32]{},
-	}
+		reflect.TypeOf(RawBytes{}):       ByteSliceStrategy{},
+	})
 }
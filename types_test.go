@@ -359,6 +359,23 @@ func TestByteSliceStrategy_SetValue(t *testing.T) {
 			want:    reflect.ValueOf([]byte("")),
 			wantErr: false,
 		},
+		{
+			name: "default is kept raw",
+			args: args{
+				field:     reflect.New(reflect.TypeOf([]byte{})).Elem(),
+				envValue:  "",
+				tagOption: &DefaultOption{DefaultValue: "foo,bar"},
+			},
+			want: reflect.ValueOf([]byte("foo,bar")),
+		},
+		{
+			name: "raw bytes",
+			args: args{
+				field:    reflect.New(reflect.TypeOf(RawBytes{})).Elem(),
+				envValue: "1,2,3",
+			},
+			want: reflect.ValueOf(RawBytes("1,2,3")),
+		},
 		{
 			name: "invalid byte slice",
 			args: args{
@@ -543,6 +560,15 @@ func Test_parseOptionValue(t *testing.T) {
 			want:    "default_value",
 			wantErr: false,
 		},
+		{
+			name: "present empty value keeps DefaultOption from applying",
+			args: args{
+				envValue:  "",
+				tagOption: &DefaultOption{DefaultValue: "default_value", present: true},
+			},
+			want:    "",
+			wantErr: false,
+		},
 		{
 			name: "error tag option",
 			args: args{
@@ -679,6 +705,24 @@ func TestBoolSliceStrategy_SetValue(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid element",
+			args: args{
+				field:    reflect.New(reflect.TypeOf([]bool{})).Elem(),
+				envValue: "true,yes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid element with lenient",
+			args: args{
+				field:     reflect.New(reflect.TypeOf([]bool{})).Elem(),
+				envValue:  "true,yes",
+				tagOption: &LenientOption{},
+			},
+			want:    reflect.ValueOf([]bool{true, false}),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -741,6 +785,24 @@ func TestIntSliceStrategy_SetValue(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid element",
+			args: args{
+				field:    reflect.New(reflect.TypeOf([]int{})).Elem(),
+				envValue: "80,abc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid element with lenient",
+			args: args{
+				field:     reflect.New(reflect.TypeOf([]int{})).Elem(),
+				envValue:  "80,abc",
+				tagOption: &LenientOption{},
+			},
+			want:    reflect.ValueOf([]int{80, 0}),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -803,6 +865,24 @@ func TestUintSliceStrategy_SetValue(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid element",
+			args: args{
+				field:    reflect.New(reflect.TypeOf([]uint{})).Elem(),
+				envValue: "80,abc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid element with lenient",
+			args: args{
+				field:     reflect.New(reflect.TypeOf([]uint{})).Elem(),
+				envValue:  "80,abc",
+				tagOption: &LenientOption{},
+			},
+			want:    reflect.ValueOf([]uint{80, 0}),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -865,6 +945,24 @@ func TestFloatSliceStrategy_SetValue(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid element",
+			args: args{
+				field:    reflect.New(reflect.TypeOf([]float64{})).Elem(),
+				envValue: "1.5,abc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid element with lenient",
+			args: args{
+				field:     reflect.New(reflect.TypeOf([]float64{})).Elem(),
+				envValue:  "1.5,abc",
+				tagOption: &LenientOption{},
+			},
+			want:    reflect.ValueOf([]float64{1.5, 0}),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -916,7 +1014,7 @@ func TestRegisterStrategy(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			RegisterStrategy(tt.args.strategyType, tt.args.strategy)
 
-			registeredStrategy, exists := complexTypeStrategies[tt.args.strategyType]
+			registeredStrategy, exists := complexTypeStrategies.Get(tt.args.strategyType)
 			if exists != tt.wantRegistered {
 				t.Errorf("expected registered = %v, got %v", tt.wantRegistered, exists)
 			}
@@ -927,3 +1025,60 @@ func TestRegisterStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestNumberStrategies_SetValue_Range(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy TypeStrategy
+		field    reflect.Value
+		envValue string
+	}{
+		{
+			name:     "int8 overflow",
+			strategy: IntStrategy[int8]{},
+			field:    reflect.New(reflect.TypeOf(int8(0))).Elem(),
+			envValue: "300",
+		},
+		{
+			name:     "uint16 overflow",
+			strategy: UintStrategy[uint16]{},
+			field:    reflect.New(reflect.TypeOf(uint16(0))).Elem(),
+			envValue: "70000",
+		},
+		{
+			name:     "float32 overflow",
+			strategy: FloatStrategy[float32]{},
+			field:    reflect.New(reflect.TypeOf(float32(0))).Elem(),
+			envValue: "1e39",
+		},
+		{
+			name:     "int8 slice overflow",
+			strategy: IntSliceStrategy[int8]{},
+			field:    reflect.New(reflect.TypeOf([]int8{})).Elem(),
+			envValue: "1,300",
+		},
+		{
+			name:     "uint8 slice overflow",
+			strategy: UintSliceStrategy[uint8]{},
+			field:    reflect.New(reflect.TypeOf([]uint8{})).Elem(),
+			envValue: "1,256",
+		},
+		{
+			name:     "float32 slice overflow",
+			strategy: FloatSliceStrategy[float32]{},
+			field:    reflect.New(reflect.TypeOf([]float32{})).Elem(),
+			envValue: "1,1e39",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.strategy.SetValue(tt.field, tt.envValue, nil)
+
+			var rangeErr *RangeError
+			if !errors.As(err, &rangeErr) {
+				t.Fatalf("SetValue() error = %v, want *RangeError", err)
+			}
+			assert.True(t, tt.field.IsZero(), "field should be left untouched, got %v", tt.field.Interface())
+		})
+	}
+}
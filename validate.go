@@ -0,0 +1,459 @@
+package env_config
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	_ TagOptionValidator = &MinOption{}
+	_ TagOptionValidator = &MaxOption{}
+	_ TagOptionValidator = &LenOption{}
+	_ TagOptionValidator = &MinLenOption{}
+	_ TagOptionValidator = &MaxLenOption{}
+	_ TagOptionValidator = &OneOfOption{}
+	_ TagOptionValidator = &PatternOption{}
+
+	_ TagOptionVerifier = &MinOption{}
+	_ TagOptionVerifier = &MaxOption{}
+	_ TagOptionVerifier = &LenOption{}
+	_ TagOptionVerifier = &MinLenOption{}
+	_ TagOptionVerifier = &MaxLenOption{}
+	_ TagOptionVerifier = &PatternOption{}
+
+	_ TagOptionTypeVerifier = &MinOption{}
+	_ TagOptionTypeVerifier = &MaxOption{}
+)
+
+type MinOptionBuilder struct{}
+
+func (m *MinOptionBuilder) Build() TagOption {
+	return &MinOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type MaxOptionBuilder struct{}
+
+func (m *MaxOptionBuilder) Build() TagOption {
+	return &MaxOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type LenOptionBuilder struct{}
+
+func (l *LenOptionBuilder) Build() TagOption {
+	return &LenOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type MinLenOptionBuilder struct{}
+
+func (m *MinLenOptionBuilder) Build() TagOption {
+	return &MinLenOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type MaxLenOptionBuilder struct{}
+
+func (m *MaxLenOptionBuilder) Build() TagOption {
+	return &MaxLenOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type OneOfOptionBuilder struct{}
+
+func (o *OneOfOptionBuilder) Build() TagOption {
+	return &OneOfOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+type PatternOptionBuilder struct{}
+
+func (p *PatternOptionBuilder) Build() TagOption {
+	return &PatternOption{
+		BaseTagOption: BaseTagOption{},
+	}
+}
+
+// MinOption requires a number or time.Duration to be at least Min.
+type MinOption struct {
+	BaseTagOption
+	Min string
+}
+
+func (m *MinOption) Next() TagOption {
+	return m.next
+}
+
+func (m *MinOption) SetValue(value string) {
+	m.Min = value
+}
+
+func (m *MinOption) Priority() int {
+	return PriorityValidator
+}
+
+func (m *MinOption) Verify() error {
+	return verifyNumber(m.Min)
+}
+
+func (m *MinOption) VerifyType(typ reflect.Type) error {
+	return verifyNumberType(Min, m.Min, typ)
+}
+
+func (m *MinOption) Validate(field reflect.Value) error {
+	c, err := compareNumber(field, m.Min)
+	if err != nil {
+		return newValidationError(Min, m.Min, field, err)
+	}
+	if c < 0 {
+		return newValidationError(Min, m.Min, field, nil)
+	}
+	return nil
+}
+
+// MaxOption requires a number or time.Duration to be at most Max.
+type MaxOption struct {
+	BaseTagOption
+	Max string
+}
+
+func (m *MaxOption) Next() TagOption {
+	return m.next
+}
+
+func (m *MaxOption) SetValue(value string) {
+	m.Max = value
+}
+
+func (m *MaxOption) Priority() int {
+	return PriorityValidator
+}
+
+func (m *MaxOption) Verify() error {
+	return verifyNumber(m.Max)
+}
+
+func (m *MaxOption) VerifyType(typ reflect.Type) error {
+	return verifyNumberType(Max, m.Max, typ)
+}
+
+func (m *MaxOption) Validate(field reflect.Value) error {
+	c, err := compareNumber(field, m.Max)
+	if err != nil {
+		return newValidationError(Max, m.Max, field, err)
+	}
+	if c > 0 {
+		return newValidationError(Max, m.Max, field, nil)
+	}
+	return nil
+}
+
+// LenOption requires a string, slice or map to have exactly Len elements.
+// Strings are measured in characters.
+type LenOption struct {
+	BaseTagOption
+	Len string
+}
+
+func (l *LenOption) Next() TagOption {
+	return l.next
+}
+
+func (l *LenOption) SetValue(value string) {
+	l.Len = value
+}
+
+func (l *LenOption) Priority() int {
+	return PriorityValidator
+}
+
+func (l *LenOption) Verify() error {
+	return verifyLen(l.Len)
+}
+
+func (l *LenOption) Validate(field reflect.Value) error {
+	c, err := compareLen(field, l.Len)
+	if err != nil {
+		return newValidationError(Len, l.Len, field, err)
+	}
+	if c != 0 {
+		return newValidationError(Len, l.Len, field, nil)
+	}
+	return nil
+}
+
+// MinLenOption requires a string, slice or map to have at least MinLen
+// elements.
+type MinLenOption struct {
+	BaseTagOption
+	MinLen string
+}
+
+func (m *MinLenOption) Next() TagOption {
+	return m.next
+}
+
+func (m *MinLenOption) SetValue(value string) {
+	m.MinLen = value
+}
+
+func (m *MinLenOption) Priority() int {
+	return PriorityValidator
+}
+
+func (m *MinLenOption) Verify() error {
+	return verifyLen(m.MinLen)
+}
+
+func (m *MinLenOption) Validate(field reflect.Value) error {
+	c, err := compareLen(field, m.MinLen)
+	if err != nil {
+		return newValidationError(MinLen, m.MinLen, field, err)
+	}
+	if c < 0 {
+		return newValidationError(MinLen, m.MinLen, field, nil)
+	}
+	return nil
+}
+
+// MaxLenOption requires a string, slice or map to have at most MaxLen
+// elements.
+type MaxLenOption struct {
+	BaseTagOption
+	MaxLen string
+}
+
+func (m *MaxLenOption) Next() TagOption {
+	return m.next
+}
+
+func (m *MaxLenOption) SetValue(value string) {
+	m.MaxLen = value
+}
+
+func (m *MaxLenOption) Priority() int {
+	return PriorityValidator
+}
+
+func (m *MaxLenOption) Verify() error {
+	return verifyLen(m.MaxLen)
+}
+
+func (m *MaxLenOption) Validate(field reflect.Value) error {
+	c, err := compareLen(field, m.MaxLen)
+	if err != nil {
+		return newValidationError(MaxLen, m.MaxLen, field, err)
+	}
+	if c > 0 {
+		return newValidationError(MaxLen, m.MaxLen, field, nil)
+	}
+	return nil
+}
+
+// OneOfOption requires the value, or every element of a slice, to be one of
+// the |-separated Values, e.g. oneof=debug|info|warn.
+type OneOfOption struct {
+	BaseTagOption
+	Values []string
+}
+
+func (o *OneOfOption) Next() TagOption {
+	return o.next
+}
+
+func (o *OneOfOption) SetValue(value string) {
+	o.Values = strings.Split(value, "|")
+}
+
+func (o *OneOfOption) Priority() int {
+	return PriorityValidator
+}
+
+func (o *OneOfOption) Validate(field reflect.Value) error {
+	for _, elem := range fieldElements(field) {
+		found := false
+		value := formatValue(elem)
+		for _, allowed := range o.Values {
+			if value == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return newValidationError(OneOf, strings.Join(o.Values, "|"), field, nil)
+		}
+	}
+	return nil
+}
+
+// PatternOption requires a string, or every element of a string slice, to
+// match the regular expression Pattern. The expression is not anchored.
+type PatternOption struct {
+	BaseTagOption
+	Pattern string
+	regexp  *regexp.Regexp
+	err     error
+}
+
+func (p *PatternOption) Next() TagOption {
+	return p.next
+}
+
+func (p *PatternOption) SetValue(value string) {
+	p.Pattern = value
+	p.regexp, p.err = regexp.Compile(value)
+}
+
+func (p *PatternOption) Priority() int {
+	return PriorityValidator
+}
+
+func (p *PatternOption) Verify() error {
+	return p.err
+}
+
+func (p *PatternOption) Validate(field reflect.Value) error {
+	if p.err != nil {
+		return newValidationError(Pattern, p.Pattern, field, p.err)
+	}
+	for _, elem := range fieldElements(field) {
+		if elem.Kind() != reflect.String {
+			return newValidationError(Pattern, p.Pattern, field, fmt.Errorf("not supported for %s", field.Type()))
+		}
+		if !p.regexp.MatchString(elem.String()) {
+			return newValidationError(Pattern, p.Pattern, field, nil)
+		}
+	}
+	return nil
+}
+
+// verifyNumber reports a min= or max= parameter that can be compared with no
+// field type: it must be a number or a duration.
+func verifyNumber(param string) error {
+	if _, err := strconv.ParseFloat(param, 64); err == nil {
+		return nil
+	}
+	if _, err := time.ParseDuration(param); err == nil {
+		return nil
+	}
+	return fmt.Errorf("%q is neither a number nor a duration", param)
+}
+
+// verifyNumberType reports a min= or max= parameter that compareNumber cannot
+// parse for a field of type typ, e.g. min=1.5 on an int or min=-1 on a uint.
+func verifyNumberType(rule, param string, typ reflect.Type) error {
+	if _, err := compareNumber(reflect.Zero(typ), param); err != nil {
+		return fmt.Errorf("option %q: %q is not a valid bound for %s: %w", rule, param, typ, err)
+	}
+	return nil
+}
+
+// verifyLen reports a length parameter that is not a non-negative integer.
+func verifyLen(param string) error {
+	if n, err := strconv.Atoi(param); err != nil || n < 0 {
+		return fmt.Errorf("%q is not a valid length", param)
+	}
+	return nil
+}
+
+// compareNumber compares a numeric or time.Duration field with param, parsed
+// as the same kind of value.
+func compareNumber(field reflect.Value, param string) (int, error) {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(field.Int(), int64(d)), nil
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(field.Int(), n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(field.Uint(), n), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(field.Float(), f), nil
+	}
+	return 0, fmt.Errorf("not supported for %s", field.Type())
+}
+
+// compareLen compares the length of a string, slice or map field with param.
+func compareLen(field reflect.Value, param string) (int, error) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return cmp.Compare(utf8.RuneCountInString(field.String()), n), nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return cmp.Compare(field.Len(), n), nil
+	}
+	return 0, fmt.Errorf("not supported for %s", field.Type())
+}
+
+// fieldElements returns the elements of a slice field, or the field itself
+// for scalars. Byte slices loaded as raw bytes, like RawBytes, are scalars.
+func fieldElements(field reflect.Value) []reflect.Value {
+	if field.Kind() != reflect.Slice || isRawBytes(field.Type()) {
+		return []reflect.Value{field}
+	}
+
+	elems := make([]reflect.Value, field.Len())
+	for i := range elems {
+		elems[i] = field.Index(i)
+	}
+	return elems
+}
+
+func formatValue(field reflect.Value) string {
+	switch {
+	case field.Kind() == reflect.String:
+		return field.String()
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		return string(field.Bytes())
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+func newValidationError(rule, param string, field reflect.Value, err error) *ValidationError {
+	return &ValidationError{
+		Rule:  rule,
+		Param: param,
+		Value: formatValue(field),
+		Err:   err,
+	}
+}
+
+// isRawBytes reports whether typ is a byte slice that ByteSliceStrategy
+// loads, i.e. any byte slice type other than []uint8 itself.
+func isRawBytes(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 && typ != reflect.TypeOf([]uint8{})
+}
@@ -0,0 +1,176 @@
+package env_config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildOption(name, value string) TagOption {
+	builder, _ := tagOptionBuilders.Get(name)
+	option := builder.Build()
+	option.SetValue(value)
+	return option
+}
+
+func TestValidators_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      TagOption
+		value       interface{}
+		wantErr     bool
+		wantRuleErr bool
+	}{
+		{name: "min int ok", option: buildOption(Min, "1"), value: 1},
+		{name: "min int fail", option: buildOption(Min, "1"), value: 0, wantErr: true},
+		{name: "min uint fail", option: buildOption(Min, "10"), value: uint8(9), wantErr: true},
+		{name: "min float ok", option: buildOption(Min, "0.5"), value: 0.75},
+		{name: "min duration ok", option: buildOption(Min, "1s"), value: 2 * time.Second},
+		{name: "min duration fail", option: buildOption(Min, "1s"), value: 500 * time.Millisecond, wantErr: true},
+		{name: "min invalid param", option: buildOption(Min, "abc"), value: 1, wantErr: true, wantRuleErr: true},
+		{name: "min unsupported type", option: buildOption(Min, "1"), value: "a", wantErr: true, wantRuleErr: true},
+		{name: "max int ok", option: buildOption(Max, "65535"), value: 65535},
+		{name: "max int fail", option: buildOption(Max, "65535"), value: 70000, wantErr: true},
+		{name: "max float fail", option: buildOption(Max, "1"), value: float32(1.5), wantErr: true},
+		{name: "max duration fail", option: buildOption(Max, "1m"), value: time.Hour, wantErr: true},
+		{name: "len string ok", option: buildOption(Len, "3"), value: "héé"},
+		{name: "len string fail", option: buildOption(Len, "3"), value: "ab", wantErr: true},
+		{name: "len slice ok", option: buildOption(Len, "2"), value: []int{1, 2}},
+		{name: "len unsupported type", option: buildOption(Len, "2"), value: 12, wantErr: true, wantRuleErr: true},
+		{name: "minlen ok", option: buildOption(MinLen, "2"), value: "ab"},
+		{name: "minlen fail", option: buildOption(MinLen, "2"), value: []string{"a"}, wantErr: true},
+		{name: "maxlen ok", option: buildOption(MaxLen, "2"), value: []string{"a"}},
+		{name: "maxlen fail", option: buildOption(MaxLen, "2"), value: "abc", wantErr: true},
+		{name: "maxlen invalid param", option: buildOption(MaxLen, "x"), value: "abc", wantErr: true, wantRuleErr: true},
+		{name: "oneof string ok", option: buildOption(OneOf, "debug|info"), value: "info"},
+		{name: "oneof string fail", option: buildOption(OneOf, "debug|info"), value: "trace", wantErr: true},
+		{name: "oneof int ok", option: buildOption(OneOf, "1|2|3"), value: 2},
+		{name: "oneof slice ok", option: buildOption(OneOf, "a|b"), value: []string{"a", "b", "a"}},
+		{name: "oneof slice fail", option: buildOption(OneOf, "a|b"), value: []string{"a", "c"}, wantErr: true},
+		{name: "pattern ok", option: buildOption(Pattern, "^[a-z]+$"), value: "abc"},
+		{name: "pattern fail", option: buildOption(Pattern, "^[a-z]+$"), value: "ABC", wantErr: true},
+		{name: "pattern slice fail", option: buildOption(Pattern, "^[a-z]+$"), value: []string{"a", "B"}, wantErr: true},
+		{name: "pattern invalid", option: buildOption(Pattern, "("), value: "abc", wantErr: true, wantRuleErr: true},
+		{name: "pattern unsupported type", option: buildOption(Pattern, "1"), value: 1, wantErr: true, wantRuleErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.option.(TagOptionValidator).Validate(reflect.ValueOf(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %T, want *ValidationError", err)
+			}
+			if (validationErr.Err != nil) != tt.wantRuleErr {
+				t.Errorf("ValidationError.Err = %v, wantRuleErr %v", validationErr.Err, tt.wantRuleErr)
+			}
+		})
+	}
+}
+
+type ValidatedConfig struct {
+	Port     int           `env:"PORT;min=1;max=65535"`
+	Timeout  time.Duration `env:"TIMEOUT;default=500ms;min=1s"`
+	Level    string        `env:"LEVEL;oneof=debug|info|warn"`
+	Name     string        `env:"NAME;pattern=^[a-z]+$"`
+	Hosts    []string      `env:"HOSTS;minlen=1"`
+	Token    string        `env:"TOKEN;len=4;sensitive"`
+	Optional int           `env:"OPTIONAL;min=1"`
+}
+
+func TestLoadConfig_Validation(t *testing.T) {
+	err := LoadConfig(&ValidatedConfig{}, WithSource(MapSource{
+		"PORT":  "70000",
+		"LEVEL": "trace",
+		"NAME":  "Name",
+		"HOSTS": "",
+		"TOKEN": "secret",
+	}))
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("LoadConfig() error = %v, want *MultiError", err)
+	}
+
+	var rules []string
+	for _, err := range multi.Errors {
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("error = %v, want *ValidationError", err)
+		}
+		rules = append(rules, validationErr.Key+" "+validationErr.Rule)
+	}
+	assert.Equal(t, []string{"PORT max", "TIMEOUT min", "LEVEL oneof", "NAME pattern", "HOSTS minlen", "TOKEN len"}, rules)
+	assert.NotContains(t, err.Error(), "secret")
+	assert.Contains(t, err.Error(), "ValidatedConfig.Port")
+}
+
+func TestNewStruct_MalformedValidator(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT;min=abc"`
+	}
+
+	// The key is absent: the tag is still rejected before loading.
+	_, err := NewStruct(&config{}, "", WithSource(MapSource{}))
+
+	var tagErr *TagError
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("NewStruct() error = %v, want *TagError", err)
+	}
+	assert.Equal(t, "config.Port", tagErr.Path)
+}
+
+func TestNewStruct_ValidatorFieldType(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     interface{}
+		wantErr bool
+	}{
+		{name: "float bound on int", cfg: &struct {
+			N int `env:"N;min=1.5"`
+		}{}, wantErr: true},
+		{name: "duration bound on int", cfg: &struct {
+			N int `env:"N;min=5s"`
+		}{}, wantErr: true},
+		{name: "negative bound on uint", cfg: &struct {
+			N uint `env:"N;min=-1"`
+		}{}, wantErr: true},
+		{name: "number bound on duration", cfg: &struct {
+			D time.Duration `env:"D;max=10"`
+		}{}, wantErr: true},
+		{name: "bound on string", cfg: &struct {
+			S string `env:"S;max=10"`
+		}{}, wantErr: true},
+		{name: "int bound on int pointer", cfg: &struct {
+			N *int `env:"N;min=-1;max=10"`
+		}{}},
+		{name: "float bound on float", cfg: &struct {
+			F float64 `env:"F;min=1.5"`
+		}{}},
+		{name: "duration bound on duration", cfg: &struct {
+			D time.Duration `env:"D;min=5s"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStruct(tt.cfg, "", WithSource(MapSource{}))
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var tagErr *TagError
+			if !errors.As(err, &tagErr) {
+				t.Fatalf("NewStruct() error = %v, want *TagError", err)
+			}
+		})
+	}
+}
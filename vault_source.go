@@ -0,0 +1,444 @@
+package env_config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// VaultTag is the tag option resolving a field from a Vault KV v2 secret,
+// written vault=<path>#<key>.
+const VaultTag = "vault"
+
+var (
+	_ FallibleSource   = &VaultSource{}
+	_ OriginSource     = &VaultSource{}
+	_ ContextSource    = &VaultSource{}
+	_ Prefetcher       = &VaultSource{}
+	_ TagOptionBuilder = &VaultSource{}
+
+	_ TagOptionResolver        = &VaultOption{}
+	_ TagOptionContextResolver = &VaultOption{}
+	_ TagOptionVerifier        = &VaultOption{}
+	_ Prefetcher               = &VaultOption{}
+)
+
+// errVaultNotFound is returned by VaultSource.do for a 404 response.
+var errVaultNotFound = errors.New("not found")
+
+// VaultSource reads secrets from a HashiCorp Vault KV version 2 engine over
+// its HTTP API. It can be used in two ways, alone or together:
+//
+//   - as a Source, serving the keys of the secrets given by WithVaultPaths
+//     with normalized names, so {"db-password": "x"} serves DB_PASSWORD;
+//     names that normalize to the same key, such as db-password and
+//     db_password, fail the lookup instead of one being picked at random;
+//   - as the builder of the vault= tag option, registered with
+//     WithTagOption(VaultTag, vault), to resolve single fields:
+//     `env:"DB_PASSWORD;vault=app/database#password"`.
+//
+// Secrets are read on first use and cached for the life of the source.
+type VaultSource struct {
+	address    string
+	token      string
+	namespace  string
+	mount      string
+	paths      []string
+	roleID     string
+	secretID   string
+	httpClient *http.Client
+
+	tokenMu sync.Mutex
+
+	secretsMu sync.Mutex
+	secrets   map[string]*vaultSecret
+}
+
+type vaultSecret struct {
+	mu     sync.Mutex
+	loaded bool
+	found  bool
+	data   map[string]string
+}
+
+// VaultSourceOption configures a VaultSource.
+type VaultSourceOption func(*VaultSource)
+
+// WithVaultAddress sets the Vault address, $VAULT_ADDR by default.
+func WithVaultAddress(address string) VaultSourceOption {
+	return func(s *VaultSource) {
+		s.address = address
+	}
+}
+
+// WithVaultToken authenticates with a token, $VAULT_TOKEN by default.
+func WithVaultToken(token string) VaultSourceOption {
+	return func(s *VaultSource) {
+		s.token = token
+	}
+}
+
+// WithVaultAppRole authenticates with the AppRole method mounted at
+// auth/approle. The source logs in on first use, and again when its token is
+// rejected.
+func WithVaultAppRole(roleID, secretID string) VaultSourceOption {
+	return func(s *VaultSource) {
+		s.roleID = roleID
+		s.secretID = secretID
+	}
+}
+
+// WithVaultNamespace sets the Vault Enterprise namespace, $VAULT_NAMESPACE
+// by default.
+func WithVaultNamespace(namespace string) VaultSourceOption {
+	return func(s *VaultSource) {
+		s.namespace = namespace
+	}
+}
+
+// WithVaultMount sets the path the KV v2 engine is mounted at, "secret" by
+// default.
+func WithVaultMount(mount string) VaultSourceOption {
+	return func(s *VaultSource) {
+		s.mount = strings.Trim(mount, "/")
+	}
+}
+
+// WithVaultPaths sets the secrets, relative to the mount, whose keys the
+// source serves through Lookup. Later paths override earlier ones.
+func WithVaultPaths(paths ...string) VaultSourceOption {
+	return func(s *VaultSource) {
+		s.paths = append(s.paths, paths...)
+	}
+}
+
+// WithVaultHTTPClient sets the HTTP client, http.DefaultClient by default.
+func WithVaultHTTPClient(client *http.Client) VaultSourceOption {
+	return func(s *VaultSource) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// NewVaultSource creates a VaultSource. Nothing is read until a key is
+// looked up.
+func NewVaultSource(opts ...VaultSourceOption) (*VaultSource, error) {
+	s := &VaultSource{
+		address:    os.Getenv("VAULT_ADDR"),
+		token:      os.Getenv("VAULT_TOKEN"),
+		namespace:  os.Getenv("VAULT_NAMESPACE"),
+		mount:      "secret",
+		httpClient: http.DefaultClient,
+		secrets:    map[string]*vaultSecret{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.address = strings.TrimRight(s.address, "/")
+	if s.address == "" {
+		return nil, errors.New("env_config: vault address is not set")
+	}
+	if s.token == "" && s.roleID == "" {
+		return nil, errors.New("env_config: vault needs a token or AppRole credentials")
+	}
+	return s, nil
+}
+
+func (s *VaultSource) Lookup(key string) (string, bool) {
+	value, ok, _ := s.LookupErr(key)
+	return value, ok
+}
+
+// LookupErr looks key up in the secrets given by WithVaultPaths, the last one
+// first.
+func (s *VaultSource) LookupErr(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key)
+}
+
+func (s *VaultSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	_, value, ok, err := s.find(ctx, key)
+	return value, ok, err
+}
+
+// Prefetch reads the secrets given by WithVaultPaths in parallel.
+func (s *VaultSource) Prefetch(ctx context.Context) error {
+	prefetchers := make([]Prefetcher, len(s.paths))
+	for i, path := range s.paths {
+		prefetchers[i] = &VaultOption{source: s, Path: path}
+	}
+	return errors.Join(prefetchAll(ctx, prefetchers)...)
+}
+
+// Origin reports the secret holding key, as vault:<path>.
+func (s *VaultSource) Origin(key string) (string, bool) {
+	path, _, ok, err := s.find(context.Background(), key)
+	if !ok || err != nil {
+		return "", false
+	}
+	return "vault:" + path, true
+}
+
+func (s *VaultSource) find(ctx context.Context, key string) (string, string, bool, error) {
+	for i := len(s.paths) - 1; i >= 0; i-- {
+		data, _, err := s.secret(ctx, s.paths[i])
+		if err != nil {
+			return "", "", false, err
+		}
+		var found []string
+		for name := range data {
+			if normalizeKey(name) == key {
+				found = append(found, name)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return s.paths[i], data[found[0]], true, nil
+		}
+		sort.Strings(found)
+		return "", "", false, fmt.Errorf("vault: read %s: names %q all map to %s", s.paths[i], found, key)
+	}
+	return "", "", false, nil
+}
+
+// Build creates the vault= tag option.
+func (s *VaultSource) Build() TagOption {
+	return &VaultOption{source: s}
+}
+
+// secret returns the data of the latest version of the secret at path. A
+// missing secret is not an error.
+func (s *VaultSource) secret(ctx context.Context, path string) (map[string]string, bool, error) {
+	path = strings.Trim(path, "/")
+
+	s.secretsMu.Lock()
+	secret, ok := s.secrets[path]
+	if !ok {
+		secret = &vaultSecret{}
+		s.secrets[path] = secret
+	}
+	s.secretsMu.Unlock()
+
+	secret.mu.Lock()
+	defer secret.mu.Unlock()
+	if secret.loaded {
+		return secret.data, secret.found, nil
+	}
+	failures := fetchFailuresFrom(ctx)
+	if err := failures.get(secret); err != nil {
+		return nil, false, err
+	}
+
+	var response struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	err := s.do(ctx, http.MethodGet, s.mount+"/data/"+path, nil, &response)
+	switch {
+	case errors.Is(err, errVaultNotFound):
+	case err != nil:
+		err = fmt.Errorf("vault: read %s: %w", path, err)
+		failures.add(ctx, secret, err)
+		return nil, false, err
+	default:
+		secret.found = true
+		secret.data = make(map[string]string, len(response.Data.Data))
+		for name, value := range response.Data.Data {
+			secret.data[name] = vaultString(value)
+		}
+	}
+	secret.loaded = true
+	return secret.data, secret.found, nil
+}
+
+// do sends a request to the Vault API, logging in with AppRole first when
+// needed, and decodes the JSON response into out.
+func (s *VaultSource) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := s.currentToken(ctx, "")
+	if err != nil {
+		return err
+	}
+	status, err := s.send(ctx, method, path, token, body, out)
+	if status == http.StatusForbidden && s.roleID != "" {
+		// The token may have expired: log in again once.
+		if token, err = s.currentToken(ctx, token); err != nil {
+			return err
+		}
+		_, err = s.send(ctx, method, path, token, body, out)
+	}
+	return err
+}
+
+// currentToken returns the token to use, logging in with AppRole when there
+// is none yet or when the current one equals rejected.
+func (s *VaultSource) currentToken(ctx context.Context, rejected string) (string, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	if s.token != "" && (rejected == "" || s.token != rejected) {
+		return s.token, nil
+	}
+	if s.roleID == "" {
+		return s.token, nil
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	login := map[string]string{"role_id": s.roleID, "secret_id": s.secretID}
+	if _, err := s.send(ctx, http.MethodPost, "auth/approle/login", "", login, &response); err != nil {
+		return "", fmt.Errorf("approle login: %w", err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", errors.New("approle login returned no token")
+	}
+	s.token = response.Auth.ClientToken
+	return s.token, nil
+}
+
+func (s *VaultSource) send(ctx context.Context, method, path, token string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	endpoint := s.address + "/v1/" + (&url.URL{Path: path}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, errVaultNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		if len(failure.Errors) > 0 {
+			return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, strings.Join(failure.Errors, "; "))
+		}
+		return resp.StatusCode, errors.New(resp.Status)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	return resp.StatusCode, decoder.Decode(out)
+}
+
+// vaultString turns a secret value into a raw string. Arrays of scalars are
+// joined with Comma like in file sources; other objects and arrays are kept as
+// JSON.
+func vaultString(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		elements := make([]string, 0, len(v))
+		for _, element := range v {
+			switch element.(type) {
+			case map[string]interface{}, []interface{}:
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			elements = append(elements, scalarString(element))
+		}
+		return strings.Join(elements, Comma)
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return scalarString(v)
+	}
+}
+
+// VaultOption resolves a field from a single key of a Vault secret. Its value
+// is <path>#<key>, with path relative to the KV mount of the VaultSource that
+// built it. A missing secret or key leaves the field unset, so default= and
+// required apply as usual.
+type VaultOption struct {
+	BaseTagOption
+	source *VaultSource
+	Path   string
+	Key    string
+}
+
+func (v *VaultOption) Next() TagOption {
+	return v.next
+}
+
+func (v *VaultOption) SetValue(value string) {
+	v.Path, v.Key, _ = strings.Cut(value, "#")
+}
+
+func (v *VaultOption) Priority() int {
+	return PriorityFlag
+}
+
+func (v *VaultOption) Resolve(key string) (string, bool, error) {
+	return v.ResolveContext(context.Background(), key)
+}
+
+func (v *VaultOption) Verify() error {
+	if v.Path == "" || v.Key == "" {
+		return fmt.Errorf("%q must be written <path>#<key>", v.Path+"#"+v.Key)
+	}
+	return nil
+}
+
+func (v *VaultOption) ResolveContext(ctx context.Context, _ string) (string, bool, error) {
+	if err := v.Verify(); err != nil {
+		return "", false, fmt.Errorf("vault: %w", err)
+	}
+	data, _, err := v.source.secret(ctx, v.Path)
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := data[v.Key]
+	return value, ok, nil
+}
+
+// Prefetch reads the secret ahead of the lookup.
+func (v *VaultOption) Prefetch(ctx context.Context) error {
+	if v.Path == "" {
+		return nil
+	}
+	_, _, err := v.source.secret(ctx, v.Path)
+	return err
+}
+
+func (v *VaultOption) String() string {
+	return "vault:" + v.Path + "#" + v.Key
+}
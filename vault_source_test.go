@@ -0,0 +1,350 @@
+package env_config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVault implements the KV v2 read and AppRole login endpoints.
+type fakeVault struct {
+	t         *testing.T
+	mount     string
+	namespace string
+	roleID    string
+	secretID  string
+	secrets   map[string]map[string]interface{}
+
+	mu     sync.Mutex
+	tokens map[string]bool
+	reads  map[string]int
+	logins int
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	v := &fakeVault{
+		t:        t,
+		mount:    "secret",
+		roleID:   "role",
+		secretID: "secret-id",
+		tokens:   map[string]bool{"root": true},
+		reads:    map[string]int{},
+		secrets: map[string]map[string]interface{}{
+			"app/database": {
+				"host":        "db.internal",
+				"password":    "hunter2",
+				"port":        json.Number("5432"),
+				"db-user":     "admin",
+				"replicas":    []interface{}{"a", "b"},
+				"enabled":     true,
+				"annotations": map[string]interface{}{"team": "core"},
+			},
+			"app/api": {"token": "t0k3n", "host": "api.internal"},
+		},
+	}
+	server := httptest.NewServer(v)
+	t.Cleanup(server.Close)
+	return v, server
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Namespace") != v.namespace {
+		v.fail(w, http.StatusForbidden, "wrong namespace")
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/auth/approle/login" {
+		var login map[string]string
+		assert.NoError(v.t, json.NewDecoder(r.Body).Decode(&login))
+		if login["role_id"] != v.roleID || login["secret_id"] != v.secretID {
+			v.fail(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		v.logins++
+		token := "approle-" + string(rune('0'+v.logins))
+		v.tokens[token] = true
+		json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": token}})
+		return
+	}
+
+	if !v.tokens[r.Header.Get("X-Vault-Token")] {
+		v.fail(w, http.StatusForbidden, "permission denied")
+		return
+	}
+	prefix := "/v1/" + v.mount + "/data/"
+	if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, prefix) {
+		v.fail(w, http.StatusNotFound)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+	v.reads[path]++
+	data, ok := v.secrets[path]
+	if !ok {
+		v.fail(w, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": 3},
+		},
+	})
+}
+
+func (v *fakeVault) fail(w http.ResponseWriter, status int, errs ...string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": append([]string{}, errs...)})
+}
+
+func TestVaultSource_TagOption(t *testing.T) {
+	fake, server := newFakeVault(t)
+
+	vault, err := NewVaultSource(WithVaultAddress(server.URL+"/"), WithVaultToken("root"))
+	assert.NoError(t, err)
+
+	type Database struct {
+		Host     string   `env:"HOST;vault=app/database#host"`
+		Port     int      `env:"PORT;vault=app/database#port"`
+		Password string   `env:"PASSWORD;vault=app/database#password;sensitive"`
+		Replicas []string `env:"REPLICAS;vault=app/database#replicas"`
+		Enabled  bool     `env:"ENABLED;vault=app/database#enabled"`
+		Labels   string   `env:"LABELS;vault=app/database#annotations"`
+	}
+	type Config struct {
+		Database Database `env:"DB"`
+		Missing  string   `env:"MISSING;vault=app/database#missing;default=fallback"`
+		Absent   string   `env:"ABSENT;vault=app/absent#key;default=none"`
+		Plain    string   `env:"PLAIN"`
+	}
+
+	var (
+		cfg    Config
+		report Report
+	)
+	err = LoadConfig(&cfg,
+		WithSource(MapSource{"PLAIN": "plain", "DB_HOST": "ignored"}),
+		WithTagOption(VaultTag, vault),
+		WithReport(&report),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		Database: Database{
+			Host:     "db.internal",
+			Port:     5432,
+			Password: "hunter2",
+			Replicas: []string{"a", "b"},
+			Enabled:  true,
+			Labels:   `{"team":"core"}`,
+		},
+		Missing: "fallback",
+		Absent:  "none",
+		Plain:   "plain",
+	}, cfg)
+
+	// Each secret is read once.
+	assert.Equal(t, map[string]int{"app/database": 1, "app/absent": 1}, fake.reads)
+
+	field, _ := report.Field("Config.Database.Password")
+	assert.Equal(t, FieldReport{
+		Path: "Config.Database.Password", Key: "DB_PASSWORD", Value: redacted, Sensitive: true,
+		Origin: "vault:app/database#password",
+	}, field)
+	field, _ = report.Field("Config.Plain")
+	assert.Equal(t, "env_config.MapSource", field.Origin)
+}
+
+func TestVaultSource_Lookup(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	vault, err := NewVaultSource(
+		WithVaultAddress(server.URL),
+		WithVaultToken("root"),
+		WithVaultPaths("app/database", "/app/api/", "app/absent"),
+	)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		key         string
+		wantValue   string
+		wantOrigin  string
+		wantPresent bool
+	}{
+		{key: "PASSWORD", wantValue: "hunter2", wantOrigin: "vault:app/database", wantPresent: true},
+		{key: "DB_USER", wantValue: "admin", wantOrigin: "vault:app/database", wantPresent: true},
+		{key: "HOST", wantValue: "api.internal", wantOrigin: "vault:/app/api/", wantPresent: true},
+		{key: "MISSING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, present := vault.Lookup(tt.key)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantPresent, present)
+
+			origin, present := vault.Origin(tt.key)
+			assert.Equal(t, tt.wantOrigin, origin)
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+
+	type Config struct {
+		Token string `env:"TOKEN"`
+		Port  int    `env:"PORT"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(vault)))
+	assert.Equal(t, Config{Token: "t0k3n", Port: 5432}, cfg)
+}
+
+func TestVaultSource_LookupCollision(t *testing.T) {
+	fake, server := newFakeVault(t)
+	fake.secrets["app/dup"] = map[string]interface{}{"db-password": "a", "db_password": "b", "user": "admin"}
+
+	vault, err := NewVaultSource(WithVaultAddress(server.URL), WithVaultToken("root"), WithVaultPaths("app/dup"))
+	assert.NoError(t, err)
+
+	_, present, err := vault.LookupErr("DB_PASSWORD")
+	assert.False(t, present)
+	assert.EqualError(t, err, `vault: read app/dup: names ["db-password" "db_password"] all map to DB_PASSWORD`)
+
+	value, present, err := vault.LookupErr("USER")
+	assert.NoError(t, err)
+	assert.True(t, present)
+	assert.Equal(t, "admin", value)
+}
+
+func TestVaultSource_LoadConfigContext(t *testing.T) {
+	fake, server := newFakeVault(t)
+
+	vault, err := NewVaultSource(WithVaultAddress(server.URL), WithVaultToken("root"), WithVaultPaths("app/api"))
+	assert.NoError(t, err)
+
+	type Config struct {
+		Host  string `env:"HOST;vault=app/database#host"`
+		Token string `env:"TOKEN"`
+	}
+
+	var cfg Config
+	assert.NoError(t, LoadConfigContext(context.Background(), &cfg, WithSource(vault), WithTagOption(VaultTag, vault)))
+	assert.Equal(t, Config{Host: "db.internal", Token: "t0k3n"}, cfg)
+	assert.Equal(t, map[string]int{"app/database": 1, "app/api": 1}, fake.reads)
+
+	vault, err = NewVaultSource(WithVaultAddress(server.URL), WithVaultToken("root"), WithVaultPaths("app/api"))
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = LoadConfigContext(ctx, &Config{}, WithSource(vault), WithTagOption(VaultTag, vault))
+	var timeout *TimeoutError
+	if assert.ErrorAs(t, err, &timeout) {
+		assert.Equal(t, []string{"HOST", "TOKEN"}, timeout.Keys)
+	}
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestVaultSource_AppRoleAndNamespace(t *testing.T) {
+	fake, server := newFakeVault(t)
+	fake.namespace = "team-a"
+	fake.mount = "kv"
+
+	vault, err := NewVaultSource(
+		WithVaultAddress(server.URL),
+		WithVaultToken(""),
+		WithVaultAppRole("role", "secret-id"),
+		WithVaultNamespace("team-a"),
+		WithVaultMount("/kv/"),
+	)
+	assert.NoError(t, err)
+
+	value, err := Get[string]("TOKEN", "vault=app/api#token", WithTagOption(VaultTag, vault))
+	assert.NoError(t, err)
+	assert.Equal(t, "t0k3n", value)
+	assert.Equal(t, 1, fake.logins)
+
+	// A revoked token triggers a single new login.
+	fake.mu.Lock()
+	fake.tokens = map[string]bool{}
+	fake.mu.Unlock()
+	value, err = Get[string]("HOST", "vault=app/database#host", WithTagOption(VaultTag, vault))
+	assert.NoError(t, err)
+	assert.Equal(t, "db.internal", value)
+	assert.Equal(t, 2, fake.logins)
+}
+
+func TestVaultSource_Errors(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	t.Run("missing configuration", func(t *testing.T) {
+		t.Setenv("VAULT_ADDR", "")
+		t.Setenv("VAULT_TOKEN", "")
+		_, err := NewVaultSource()
+		assert.EqualError(t, err, "env_config: vault address is not set")
+
+		_, err = NewVaultSource(WithVaultAddress(server.URL))
+		assert.EqualError(t, err, "env_config: vault needs a token or AppRole credentials")
+	})
+
+	t.Run("environment defaults", func(t *testing.T) {
+		t.Setenv("VAULT_ADDR", server.URL)
+		t.Setenv("VAULT_TOKEN", "root")
+		t.Setenv("VAULT_NAMESPACE", "")
+		vault, err := NewVaultSource(WithVaultPaths("app/api"))
+		assert.NoError(t, err)
+		value, ok, err := vault.LookupErr("TOKEN")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "t0k3n", value)
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		vault, err := NewVaultSource(WithVaultAddress(server.URL), WithVaultToken("bad"))
+		assert.NoError(t, err)
+
+		type Config struct {
+			Password string `env:"PASSWORD;vault=app/database#password"`
+		}
+		err = LoadConfig(&Config{}, WithTagOption(VaultTag, vault))
+		var fetchErr *FetchError
+		assert.True(t, errors.As(err, &fetchErr))
+		assert.Equal(t, []string{"PASSWORD"}, fetchErr.Keys)
+		assert.EqualError(t, err, "env_config: cannot resolve PASSWORD: vault: read app/database: 403 Forbidden: permission denied")
+
+		// A failing layer is not hidden by a lower one.
+		vault, err = NewVaultSource(WithVaultAddress(server.URL), WithVaultToken("bad"), WithVaultPaths("app/database"))
+		assert.NoError(t, err)
+		_, _, err = NewCompositeSource(MapSource{"PASSWORD": "x"}, vault).LookupErr("PASSWORD")
+		assert.EqualError(t, err, "vault: read app/database: 403 Forbidden: permission denied")
+	})
+
+	t.Run("bad approle credentials", func(t *testing.T) {
+		vault, err := NewVaultSource(WithVaultAddress(server.URL), WithVaultAppRole("role", "wrong"), WithVaultPaths("app/api"))
+		assert.NoError(t, err)
+		_, _, err = vault.LookupErr("TOKEN")
+		assert.EqualError(t, err, "vault: read app/api: approle login: 400 Bad Request: invalid role or secret ID")
+	})
+
+	t.Run("malformed option", func(t *testing.T) {
+		vault, err := NewVaultSource(WithVaultAddress(server.URL), WithVaultToken("root"))
+		assert.NoError(t, err)
+		_, err = Get[string]("KEY", "vault=app/api", WithTagOption(VaultTag, vault))
+		assert.EqualError(t, err, `env_config: invalid tag "vault=app/api" on KEY: option "vault": "app/api#" must be written <path>#<key>`)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		vault, err := NewVaultSource(WithVaultAddress("http://127.0.0.1:1"), WithVaultToken("root"), WithVaultPaths("app"))
+		assert.NoError(t, err)
+		_, ok := vault.Lookup("KEY")
+		assert.False(t, ok)
+		_, _, err = vault.LookupErr("KEY")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package env_config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewYAMLSource reads a YAML mapping from path into a MapSource, flattening
+// it like NewJSONSource. Scalars keep their original text, so 0755 or 1.10
+// are not reinterpreted, and anchors, aliases and merge keys (<<) are
+// resolved. When the file holds several documents, later ones override
+// earlier ones.
+func NewYAMLSource(path string) (MapSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	source, err := ParseYAML(f)
+	if err != nil {
+		return nil, fmt.Errorf("env_config: %s: %w", path, err)
+	}
+	return source, nil
+}
+
+// ParseYAML is like NewYAMLSource but reads the documents from r.
+func ParseYAML(r io.Reader) (MapSource, error) {
+	source := MapSource{}
+	decoder := yaml.NewDecoder(r)
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return source, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := yamlValue(&document)
+		if err != nil {
+			return nil, err
+		}
+		switch value.(type) {
+		case map[string]interface{}, nil:
+		default:
+			return nil, fmt.Errorf("line %d: expected a mapping at the top level", document.Line)
+		}
+		flatten(source, "", value)
+	}
+}
+
+// yamlValue converts node into the shapes flatten understands, keeping
+// scalars as their source text.
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil, nil
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := yamlValue(child)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case yaml.MappingNode:
+		values := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			value, err := yamlValue(valueNode)
+			if err != nil {
+				return nil, err
+			}
+			if keyNode.Tag == "!!merge" {
+				if err := yamlMerge(values, value, keyNode.Line); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if keyNode.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping keys must be scalars", keyNode.Line)
+			}
+			values[keyNode.Value] = value
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// yamlMerge applies a merge key: explicit keys win over merged ones.
+func yamlMerge(values map[string]interface{}, merged interface{}, line int) error {
+	var maps []interface{}
+	switch m := merged.(type) {
+	case map[string]interface{}:
+		maps = []interface{}{m}
+	case []interface{}:
+		maps = m
+	default:
+		maps = []interface{}{m}
+	}
+	for _, m := range maps {
+		mapping, ok := m.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("line %d: merge key expects a mapping or a list of mappings", line)
+		}
+		for name, value := range mapping {
+			if _, exists := values[name]; !exists {
+				values[name] = value
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package env_config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    MapSource
+		wantErr string
+	}{
+		{
+			name: "nested mappings keep scalar text",
+			input: `
+port: 8080
+mode: 0755
+version: 1.10
+date: 2024-07-17T16:35:34+07:00
+empty:
+database:
+  host: db
+  max-conns: 10
+`,
+			want: MapSource{
+				"PORT":               "8080",
+				"MODE":               "0755",
+				"VERSION":            "1.10",
+				"DATE":               "2024-07-17T16:35:34+07:00",
+				"EMPTY":              "",
+				"DATABASE_HOST":      "db",
+				"DATABASE_MAX_CONNS": "10",
+			},
+		},
+		{
+			name: "sequences",
+			input: `
+hosts: [a, b]
+servers:
+  - name: one
+  - name: two
+`,
+			want: MapSource{
+				"HOSTS":          "a,b",
+				"HOSTS_0":        "a",
+				"HOSTS_1":        "b",
+				"SERVERS_0_NAME": "one",
+				"SERVERS_1_NAME": "two",
+			},
+		},
+		{
+			name: "anchors, aliases and merge keys",
+			input: `
+base: &base
+  host: localhost
+  port: 5432
+primary:
+  <<: *base
+  host: primary
+replica:
+  host: replica
+  <<: [*base]
+copy: *base
+`,
+			want: MapSource{
+				"BASE_HOST":    "localhost",
+				"BASE_PORT":    "5432",
+				"PRIMARY_HOST": "primary",
+				"PRIMARY_PORT": "5432",
+				"REPLICA_HOST": "replica",
+				"REPLICA_PORT": "5432",
+				"COPY_HOST":    "localhost",
+				"COPY_PORT":    "5432",
+			},
+		},
+		{
+			name:  "later documents override earlier ones",
+			input: "a: 1\nb: 1\n---\nb: 2\n",
+			want:  MapSource{"A": "1", "B": "2"},
+		},
+		{
+			name:  "empty document",
+			input: "",
+			want:  MapSource{},
+		},
+		{
+			name:    "top-level sequence",
+			input:   "- a\n- b\n",
+			wantErr: "line 1: expected a mapping at the top level",
+		},
+		{
+			name:    "invalid merge",
+			input:   "a:\n  <<: 1\n",
+			wantErr: "line 2: merge key expects a mapping or a list of mappings",
+		},
+		{
+			name:    "malformed",
+			input:   "a: [1\n",
+			wantErr: "yaml: line 1: did not find expected ',' or ']'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseYAML(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewYAMLSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+database:
+  host: db
+  ports: [5432, 5433]
+log-level: debug
+`), 0o600))
+
+	source, err := NewYAMLSource(path)
+	assert.NoError(t, err)
+
+	type Database struct {
+		Host  string `env:"HOST"`
+		Ports []int  `env:"PORTS"`
+	}
+	type Config struct {
+		Database Database `env:"DATABASE"`
+		LogLevel string   `env:"LOG_LEVEL"`
+	}
+	var cfg Config
+	assert.NoError(t, LoadConfig(&cfg, WithSource(source)))
+	assert.Equal(t, Config{Database: Database{Host: "db", Ports: []int{5432, 5433}}, LogLevel: "debug"}, cfg)
+
+	_, err = NewYAMLSource(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.NoError(t, os.WriteFile(path, []byte("- a\n"), 0o600))
+	_, err = NewYAMLSource(path)
+	assert.EqualError(t, err, "env_config: "+path+": line 1: expected a mapping at the top level")
+}